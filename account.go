@@ -0,0 +1,49 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// Login авторизует аккаунт и сохраняет сессию, если она еще не сохранена или устарела
+func Login(ctx context.Context) error {
+	ctx = startOperation(ctx, "login", "")
+	return runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		self, err := client.Self(ctx)
+		if err != nil {
+			return fmt.Errorf("не удалось получить данные аккаунта: %w", err)
+		}
+		mahalo.Logger(ctx).Info("вошли в аккаунт", "user_id", self.ID)
+		return nil
+	})
+}
+
+// ListBots возвращает username всех ботов аккаунта (без '@') через bots.getAdminedBots
+func ListBots(ctx context.Context) (bots []string, err error) {
+	ctx = startOperation(ctx, "list", "")
+	err = runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		owned, err := mahalo.OwnedBots(ctx, api)
+		if err != nil {
+			return err
+		}
+		for _, u := range owned {
+			// У бота с коллекционным username основной может быть пустым
+			username := u.Username
+			for _, name := range u.Usernames {
+				if username == "" && name.Active {
+					username = name.Username
+				}
+			}
+			if username != "" {
+				bots = append(bots, username)
+			}
+		}
+		return nil
+	})
+	return bots, err
+}
@@ -0,0 +1,220 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// ApplyResult — итог применения манифеста
+type ApplyResult struct {
+	Username string   `json:"username"`
+	Token    string   `json:"token,omitempty"`   // заполняется, только если бот был создан
+	Created  bool     `json:"created"`           // бот создан в ходе применения
+	Applied  []string `json:"applied,omitempty"` // поля манифеста, отправленные BotFather, в порядке применения
+}
+
+// manifestField — одна настройка из манифеста и способ её применить
+type manifestField struct {
+	name  string
+	apply func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error
+}
+
+// ApplyManifest создает бота, если его еще нет, и по очереди применяет все
+// настройки манифеста в одном подключении. Если указан только base_username,
+// сначала ищется бот аккаунта с одним из вариантов этой базы, и новый бот
+// создается, только если такого нет.
+func ApplyManifest(ctx context.Context, m *Manifest) (*ApplyResult, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx = startOperation(ctx, "apply", m.Username)
+	result := &ApplyResult{}
+	err := runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		if err := ensureBot(ctx, api, m, result); err != nil {
+			return err
+		}
+		ctx = withOperationBot(ctx, result.Username)
+
+		botFather, err := mahalo.FindBotFather(ctx, api)
+		if err != nil {
+			return fmt.Errorf("не удалось найти BotFather: %w", err)
+		}
+
+		for _, field := range m.fields() {
+			// Имя только что созданного бота уже задано в /newbot
+			if field.name == "name" && result.Created {
+				continue
+			}
+			mahalo.Logger(ctx).Info("применяем поле", "step", field.name)
+			if err := field.apply(ctx, api, botFather, result.Username); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+			result.Applied = append(result.Applied, field.name)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// baseUsernameAttempts — сколько вариантов base_username перебирается при создании бота
+const baseUsernameAttempts = 5
+
+// ensureBot находит бота из манифеста или создает его, заполняя result
+func ensureBot(ctx context.Context, api *tg.Client, m *Manifest, result *ApplyResult) error {
+	var err error
+
+	if m.BaseUsername != "" {
+		base := mahalo.NormalizeBotUsername(m.BaseUsername)
+		// Повторное применение того же манифеста не должно плодить ботов
+		existing, err := findOwnedCandidate(ctx, api, base)
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			mahalo.Logger(ctx).Info("бот уже создан по base_username", "bot", existing)
+			result.Username = existing
+			return nil
+		}
+		result.Username, result.Token, err = createBotWithGenerator(ctx, api, m.Name, base, mahalo.NumericSuffix{}, baseUsernameAttempts)
+		result.Created = result.Token != ""
+		return err
+	}
+
+	username := mahalo.NormalizeBotUsername(m.Username)
+	bot, err := resolveOwnedBot(ctx, api, username)
+	if err != nil {
+		return err
+	}
+	if bot != nil {
+		result.Username = bot.Username
+		return nil
+	}
+
+	mahalo.Logger(ctx).Info("бот не найден, создаем")
+	fixed := mahalo.UsernameGeneratorFunc(func(string, int) mahalo.UsernameCandidate {
+		return mahalo.UsernameCandidate{Stem: username}
+	})
+	result.Username, result.Token, err = createBotWithGenerator(ctx, api, m.Name, username, fixed, 1)
+	result.Created = result.Token != ""
+	return err
+}
+
+// resolveOwnedBot находит бота по username и проверяет, что им владеет текущий аккаунт.
+// Если имя никем не занято, возвращает nil без ошибки.
+func resolveOwnedBot(ctx context.Context, api *tg.Client, username string) (*tg.User, error) {
+	bot, err := mahalo.ResolveBot(ctx, api, username)
+	if err != nil || bot == nil {
+		return nil, err
+	}
+
+	owned, err := mahalo.OwnedBots(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range owned {
+		if u.ID == bot.ID {
+			return bot, nil
+		}
+	}
+	return nil, fmt.Errorf("@%s принадлежит другому аккаунту: %s", username, mahalo.ErrUsernameTaken)
+}
+
+// findOwnedCandidate возвращает первого бота аккаунта среди вариантов base_username
+// в порядке их перебора при создании; "" — такого бота нет
+func findOwnedCandidate(ctx context.Context, api *tg.Client, base string) (string, error) {
+	owned, err := mahalo.OwnedBots(ctx, api)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 1; attempt <= baseUsernameAttempts; attempt++ {
+		candidate := mahalo.NumericSuffix{}.Candidate(base, attempt).String()
+		for _, u := range owned {
+			if strings.EqualFold(u.Username, candidate) {
+				return u.Username, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// fields возвращает заданные в манифесте настройки в порядке применения
+func (m *Manifest) fields() []manifestField {
+	var fields []manifestField
+	add := func(name string, apply func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error) {
+		fields = append(fields, manifestField{name: name, apply: apply})
+	}
+	dialogue := func(name string, d botFatherDialogue, text string) {
+		add(name, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error {
+			return d.run(ctx, api, botFather, botUsername, text)
+		})
+	}
+
+	profile := func(name string, s setting) {
+		add(name, s.apply)
+	}
+
+	if m.Name != "" {
+		profile("name", nameSetting(m.Name))
+	}
+	if m.Description != "" {
+		profile("description", descriptionSetting(m.Description))
+	}
+	if m.About != "" {
+		profile("about", aboutSetting(m.About))
+	}
+	if len(m.Commands) > 0 {
+		profile("commands", commandsSetting(m.Commands))
+	}
+	for _, lang := range m.languages() {
+		fields = append(fields, m.Localized[lang].fields(lang)...)
+	}
+	for _, sc := range m.ScopedCommands {
+		profile(sc.field(), sc.setting())
+	}
+	if m.Userpic != "" {
+		add("userpic", func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error {
+			return setBotUserpic(ctx, api, botFather, botUsername, m.Userpic)
+		})
+	}
+	if s := m.Settings; s != nil {
+		if s.Privacy != nil {
+			dialogue("privacy", setPrivacyDialogue, enableDisable(*s.Privacy))
+		}
+		if s.JoinGroups != nil {
+			dialogue("join_groups", setJoinGroupsDialogue, enableDisable(*s.JoinGroups))
+		}
+		if s.InlinePlaceholder != "" {
+			dialogue("inline_placeholder", setInlineDialogue, s.InlinePlaceholder)
+		}
+	}
+	if m.Domain != "" {
+		dialogue("domain", setDomainDialogue, m.Domain)
+	}
+	if b := m.MenuButton; b != nil {
+		add("menu_button", func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error {
+			return setBotMenuButton(ctx, api, botFather, botUsername, b.Text, b.URL)
+		})
+	}
+	if m.Webhook != nil {
+		profile("webhook", m.Webhook.setting())
+	}
+	if a := m.AdminRights; a != nil {
+		for _, forChannels := range []bool{false, true} {
+			// Имена прав уже проверены в Validate
+			if rights, ok, _ := a.parsed(forChannels); ok {
+				profile(adminRightsField(forChannels), adminRightsSetting(rights, forChannels))
+			}
+		}
+	}
+
+	return fields
+}
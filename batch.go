@@ -0,0 +1,292 @@
+package ohana
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// ManifestTemplate — манифест с переменными text/template, например {{.customer}}
+type ManifestTemplate struct {
+	path string
+	tmpl *template.Template
+}
+
+// BatchResult — итог создания одного бота из шаблона
+type BatchResult struct {
+	Row      int      `json:"row"` // номер строки параметров, с 1
+	Name     string   `json:"name,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Token    string   `json:"token,omitempty"`
+	Applied  []string `json:"applied,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// templateFuncs — функции, доступные в шаблоне манифеста
+var templateFuncs = template.FuncMap{
+	"quote": yamlQuote,
+}
+
+// yamlQuote превращает значение в строку YAML в двойных кавычках, чтобы кавычки,
+// двоеточия и переводы строк из параметров не ломали манифест: {{quote .name}}.
+// Строка JSON — корректная строка YAML в двойных кавычках.
+func yamlQuote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// LoadManifestTemplate читает шаблон манифеста. Отсутствующая в строке параметров
+// переменная считается ошибкой, а не пустой строкой. Значения параметров подставляются
+// как есть — в YAML их нужно оборачивать функцией quote.
+func LoadManifestTemplate(path string) (*ManifestTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать шаблон: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в шаблоне %s: %w", path, err)
+	}
+	return &ManifestTemplate{path: path, tmpl: tmpl}, nil
+}
+
+// Render подставляет параметры и возвращает проверенный манифест.
+// Относительный путь к userpic считается от каталога шаблона.
+func (t *ManifestTemplate) Render(params map[string]string) (*Manifest, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, params); err != nil {
+		return nil, fmt.Errorf("не удалось заполнить шаблон: %w", err)
+	}
+
+	m, err := decodeManifest(buf.Bytes())
+	if err != nil {
+		return nil, &ManifestError{Path: t.path, Problems: []string{err.Error()}}
+	}
+	if m.Userpic != "" && !filepath.IsAbs(m.Userpic) {
+		m.Userpic = filepath.Join(filepath.Dir(t.path), m.Userpic)
+	}
+	if err := m.Validate(); err != nil {
+		if me, ok := err.(*ManifestError); ok {
+			me.Path = t.path
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadBatchParams читает строки параметров из CSV (первая строка — заголовок)
+// или JSON (массив объектов со строковыми значениями)
+func LoadBatchParams(path string) ([]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать параметры: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var rows []map[string]string
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("ошибка разбора %s: %w", path, err)
+		}
+		return rows, nil
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора %s: %w", path, err)
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		header := records[0]
+		rows := make([]map[string]string, 0, len(records)-1)
+		for _, record := range records[1:] {
+			row := make(map[string]string, len(header))
+			for i, key := range header {
+				row[strings.TrimSpace(key)] = record[i]
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат параметров %q: ожидается .csv или .json", path)
+	}
+}
+
+// RunBatch создает по боту на каждую строку параметров: заполняет шаблон, находит бота
+// аккаунта или создает нового, как ApplyManifest с base_username (base_username или
+// username из шаблона служит базой), применяет остальные настройки и после каждой строки
+// переписывает resultsPath (.json или .csv, права 0600 — там токены). Ошибка строки
+// записывается в результат и не останавливает остальные.
+//
+// Если resultsPath уже существует (прошлый запуск прервался), строки, обработанные
+// без ошибок, пропускаются и переносятся в новые результаты вместе с токенами.
+func RunBatch(ctx context.Context, t *ManifestTemplate, rows []map[string]string, resultsPath string) ([]BatchResult, error) {
+	ctx = startOperation(ctx, "batch", "")
+
+	// Проверяем путь к результатам до того, как создавать ботов, не трогая прошлые результаты
+	switch strings.ToLower(filepath.Ext(resultsPath)) {
+	case ".json", ".csv":
+	default:
+		return nil, fmt.Errorf("неизвестный формат результатов %q: ожидается .csv или .json", resultsPath)
+	}
+	previous, err := readBatchResults(resultsPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWritable(resultsPath); err != nil {
+		return nil, err
+	}
+	done := make(map[int]BatchResult, len(previous))
+	for _, r := range previous {
+		done[r.Row] = r
+	}
+
+	results := make([]BatchResult, 0, len(rows))
+	for i, params := range rows {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		prev, ok := done[i+1]
+		if ok && prev.Error == "" && prev.Username != "" {
+			mahalo.Logger(ctx).Info("строка уже обработана, пропускаем", "row", prev.Row, "bot", prev.Username)
+			results = append(results, prev)
+			continue
+		}
+
+		res := runBatchRow(ctx, t, params)
+		res.Row = i + 1
+		// Найденный бот токена не возвращает — сохраняем токен из прошлого запуска
+		if res.Token == "" && ok && strings.EqualFold(prev.Username, res.Username) {
+			res.Token = prev.Token
+		}
+		if res.Error != "" {
+			mahalo.Logger(ctx).Warn("строка не обработана", "row", res.Row, "bot", res.Username, "error", res.Error)
+		}
+		results = append(results, res)
+
+		if err := writeBatchResults(resultsPath, results); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// runBatchRow находит или создает и настраивает одного бота
+func runBatchRow(ctx context.Context, t *ManifestTemplate, params map[string]string) BatchResult {
+	var res BatchResult
+
+	m, err := t.Render(params)
+	if err != nil {
+		res.Error = mahalo.Redact(err.Error())
+		return res
+	}
+	res.Name = m.Name
+
+	// username из шаблона — тоже база: ботов аккаунта ищем среди ее вариантов
+	if m.BaseUsername == "" {
+		m.BaseUsername, m.Username = m.Username, ""
+	}
+	applied, err := ApplyManifest(ctx, m)
+	if applied != nil {
+		res.Username, res.Token, res.Applied = applied.Username, applied.Token, applied.Applied
+	}
+	if err != nil {
+		res.Error = mahalo.Redact(err.Error())
+	}
+	return res
+}
+
+// readBatchResults читает результаты прошлого запуска; если файла нет, возвращает nil
+func readBatchResults(path string) ([]BatchResult, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать результаты: %w", err)
+	}
+
+	var results []BatchResult
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, fmt.Errorf("ошибка разбора результатов %s: %w", path, err)
+		}
+	case ".csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора результатов %s: %w", path, err)
+		}
+		for i, record := range records {
+			if i == 0 {
+				continue // заголовок
+			}
+			if len(record) != 6 {
+				return nil, fmt.Errorf("ошибка разбора результатов %s: в строке %d %d полей вместо 6", path, i+1, len(record))
+			}
+			row, err := strconv.Atoi(record[0])
+			if err != nil {
+				return nil, fmt.Errorf("ошибка разбора результатов %s: номер строки %q", path, record[0])
+			}
+			r := BatchResult{Row: row, Name: record[1], Username: record[2], Token: record[3], Error: record[5]}
+			if record[4] != "" {
+				r.Applied = strings.Fields(record[4])
+			}
+			results = append(results, r)
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный формат результатов %q: ожидается .csv или .json", path)
+	}
+	return results, nil
+}
+
+// checkWritable проверяет, что в каталог файла можно писать, не трогая сам файл
+func checkWritable(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".check-*")
+	if err != nil {
+		return fmt.Errorf("не удалось записать результаты: %w", err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// writeBatchResults атомарно записывает результаты в JSON или CSV
+func writeBatchResults(path string, results []BatchResult) error {
+	var buf bytes.Buffer
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("не удалось сериализовать результаты: %w", err)
+		}
+	case ".csv":
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"row", "name", "username", "token", "applied", "error"})
+		for _, r := range results {
+			_ = w.Write([]string{strconv.Itoa(r.Row), r.Name, r.Username, r.Token, strings.Join(r.Applied, " "), r.Error})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("не удалось сериализовать результаты: %w", err)
+		}
+	default:
+		return fmt.Errorf("неизвестный формат результатов %q: ожидается .csv или .json", path)
+	}
+
+	return mahalo.WriteFileAtomic(path, buf.Bytes(), 0o600)
+}
@@ -0,0 +1,86 @@
+package ohana
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBatchResultsRoundTrip(t *testing.T) {
+	results := []BatchResult{
+		{Row: 1, Name: "ACME Store", Username: "acme_shopbot", Token: "123:abc", Applied: []string{"description", "commands"}},
+		{Row: 2, Name: `Globex "Market"`, Error: "username занят, попробуйте позже"},
+	}
+	for _, ext := range []string{".json", ".csv"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results"+ext)
+			if err := writeBatchResults(path, results); err != nil {
+				t.Fatalf("writeBatchResults: %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0o600 {
+				t.Errorf("права %o, ожидались 0600", perm)
+			}
+
+			got, err := readBatchResults(path)
+			if err != nil {
+				t.Fatalf("readBatchResults: %v", err)
+			}
+			if !reflect.DeepEqual(got, results) {
+				t.Errorf("прочитано %+v, ожидалось %+v", got, results)
+			}
+		})
+	}
+
+	if got, err := readBatchResults(filepath.Join(t.TempDir(), "missing.json")); got != nil || err != nil {
+		t.Errorf("для отсутствующего файла: %v, %v; ожидалось nil, nil", got, err)
+	}
+}
+
+func TestRunBatchSkipsDoneRows(t *testing.T) {
+	tmpl, err := LoadManifestTemplate(filepath.Join("examples", "customer.yaml.tmpl"))
+	if err != nil {
+		t.Fatalf("LoadManifestTemplate: %v", err)
+	}
+	rows := []map[string]string{
+		{"customer": "acme", "name": "ACME Store"},
+		{"customer": "globex", "name": "Globex Market"},
+	}
+
+	// Обе строки уже обработаны: RunBatch не должен подключаться к Telegram и терять токены
+	path := filepath.Join(t.TempDir(), "results.json")
+	previous := []BatchResult{
+		{Row: 1, Name: "ACME Store", Username: "acme_shopbot", Token: "1:a"},
+		{Row: 2, Name: "Globex Market", Username: "globex_shopbot", Token: "2:b"},
+	}
+	if err := writeBatchResults(path, previous); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := RunBatch(context.Background(), tmpl, rows, path)
+	if err != nil {
+		t.Fatalf("RunBatch: %v", err)
+	}
+	if !reflect.DeepEqual(got, previous) {
+		t.Errorf("результаты %+v, ожидалось %+v", got, previous)
+	}
+	saved, err := readBatchResults(path)
+	if err != nil || !reflect.DeepEqual(saved, previous) {
+		t.Errorf("в файле %+v (%v), ожидалось %+v", saved, err, previous)
+	}
+}
+
+func TestRunBatchRejectsUnknownResultsFormat(t *testing.T) {
+	tmpl, err := LoadManifestTemplate(filepath.Join("examples", "customer.yaml.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunBatch(context.Background(), tmpl, nil, filepath.Join(t.TempDir(), "results.txt")); err == nil {
+		t.Error("ожидалась ошибка для .txt")
+	}
+}
@@ -0,0 +1,119 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// CloneResult — итог клонирования профиля бота
+type CloneResult struct {
+	Username string   `json:"username"`
+	Token    string   `json:"token,omitempty"`
+	Copied   []string `json:"copied,omitempty"`
+	// NotCopied — поля, которые перенести не удалось, с причиной
+	NotCopied map[string]string `json:"not_copied,omitempty"`
+}
+
+// CloneBot создает бота newUsername с именем newName и переносит на него описание,
+// about, команды, фото профиля, настройки и кнопку меню бота sourceUsername. Вебхук не переносится,
+// домен Bot API не отдает — его нужно задать вручную.
+// Ошибка при переносе отдельного поля не прерывает клонирование, а попадает в NotCopied.
+func CloneBot(ctx context.Context, sourceUsername, newName, newUsername string) (*CloneResult, error) {
+	sourceUsername = mahalo.NormalizeBotUsername(sourceUsername)
+	newUsername = mahalo.NormalizeBotUsername(newUsername)
+	// Имя и username проверяем до того, как читать исходного бота
+	if err := (&Manifest{Name: newName, Username: newUsername}).Validate(); err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ohana-clone-")
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать временный каталог: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	ctx = startOperation(ctx, "clone", "")
+	ctx = mahalo.WithLogger(ctx, mahalo.Logger(ctx).With("source", sourceUsername))
+	result := &CloneResult{NotCopied: map[string]string{}}
+	err = runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		botFather, err := mahalo.FindBotFather(ctx, api)
+		if err != nil {
+			return fmt.Errorf("не удалось найти BotFather: %w", err)
+		}
+
+		// 1. Читаем исходного бота
+		source, err := mahalo.ResolveBot(ctx, api, sourceUsername)
+		if err != nil {
+			return err
+		}
+		if source == nil {
+			return &mahalo.BotNotFoundError{Username: sourceUsername}
+		}
+		state, err := readBotState(ctx, api, botFather, sourceUsername, nil, nil)
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать @%s: %w", sourceUsername, err)
+		}
+
+		m := state.Manifest()
+		m.Name, m.Username = newName, newUsername
+		// Manifest вебхук не выгружает; к тому же два бота с одним вебхуком смешают обновления
+		if state.Webhook != nil {
+			result.NotCopied["webhook"] = "вебхук не переносится: у нового бота должен быть свой адрес"
+		}
+
+		// 2. Скачиваем фото профиля
+		photoPath := filepath.Join(tmpDir, "userpic.jpg")
+		if ok, err := mahalo.DownloadProfilePhoto(ctx, api, source, photoPath); err != nil {
+			result.NotCopied["userpic"] = mahalo.Redact(err.Error())
+		} else if ok {
+			m.Userpic = photoPath
+		}
+
+		// Эти поля Bot API не отдает, поэтому перенести их нельзя
+		result.NotCopied["domain"] = "не читается через Bot API, задайте вручную, если у исходного бота он был"
+		if state.Inline {
+			result.NotCopied["inline_placeholder"] = "текст inline-подсказки нельзя прочитать, inline-режим нужно включить вручную"
+		}
+
+		// Собранный манифест проверяем до создания, чтобы не оставить полунастроенного бота
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("профиль @%s нельзя перенести: %w", sourceUsername, err)
+		}
+
+		// 3. Создаем нового бота
+		fixed := mahalo.UsernameGeneratorFunc(func(string, int) mahalo.UsernameCandidate {
+			return mahalo.UsernameCandidate{Stem: newUsername}
+		})
+		result.Username, result.Token, err = createBotWithGenerator(ctx, api, newName, newUsername, fixed, 1)
+		if err != nil {
+			return err
+		}
+		ctx = withOperationBot(ctx, result.Username)
+
+		// 4. Переносим настройки; имя уже задано в /newbot
+		for _, field := range m.fields() {
+			if field.name == "name" {
+				continue
+			}
+			mahalo.Logger(ctx).Info("копируем поле", "step", field.name)
+			if err := field.apply(ctx, api, botFather, result.Username); err != nil {
+				result.NotCopied[field.name] = mahalo.Redact(err.Error())
+				continue
+			}
+			result.Copied = append(result.Copied, field.name)
+		}
+		return nil
+	})
+	if err != nil && result.Username == "" {
+		return nil, err
+	}
+	// Если бот уже создан, результат возвращается вместе с ошибкой
+	return result, err
+}
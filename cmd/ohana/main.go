@@ -0,0 +1,824 @@
+// ohana — командная строка для управления ботами через BotFather.
+//
+//	ohana [флаги] <команда> [аргументы]
+//
+// Данные аккаунта задаются флагами или переменными окружения OHANA_API_ID,
+// OHANA_API_HASH, OHANA_PHONE и OHANA_SESSION.
+//
+// Код выхода: 0 — успех, 1 — прочая ошибка, 2 — неверные аргументы,
+// 3 — нет конфигурации или авторизация не удалась, 4 — некорректные данные
+// (username, команды, манифест, токен не прошел проверку), 5 — бот не найден, 6 — username занят,
+// 7 — Telegram просит подождать, 8 — drift нашел расхождения, 9 — drift не смог
+// проверить часть манифестов, 130 — прервано.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boriuscastus/ohana"
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/ghodss/yaml"
+)
+
+// Коды выхода
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitAuth        = 3
+	exitInvalid     = 4
+	exitNotFound    = 5
+	exitTaken       = 6
+	exitRateLimited = 7
+	exitDrift       = 8
+	exitDriftErrors = 9
+	exitInterrupted = 130
+)
+
+// codeUsage — код ошибки в аргументах командной строки
+const codeUsage = "usage"
+
+// command — подкоманда: run получает аргументы после имени команды и заполняет res,
+// text печатает успешный результат в текстовом режиме
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string, res *ohana.Result) error
+	text  func(res *ohana.Result)
+}
+
+var commands = map[string]command{
+	"login":    {"login", runLogin, printOK},
+	"create":   {"create -name <имя> [-username <username> | -base <база> [-attempts N]]", runCreate, printUsernameToken},
+	"set":      {"set [-token <токен>] [-lang <код>] name|description|about|commands|userpic <бот> <значение>...", runSet, printOK},
+	"commands": {"commands [-token <токен>] [-scope <область>] [-lang <код>] get|set|delete <бот> [команда=описание]...", runCommands, printCommands},
+	"webhook":  {"webhook [-token <токен>] [-secret <секрет>] [-max-connections N] [-allowed-updates a,b] [-drop-pending] get|set|delete <бот> [url]", runWebhook, printWebhook},
+	"rights":   {"rights [-token <токен>] [-channels] get|set <бот> [право]...", runRights, printRights},
+	"delete":   {"delete -yes <бот>", runDelete, printOK},
+	"list":     {"list", runList, printLines},
+	"token":    {"token <бот>", runToken, printToken},
+	"revoke":   {"revoke <бот>", runRevoke, printToken},
+	"apply":    {"apply [-dry-run] <манифест>", runApply, printApply},
+	"export":   {"export [-o <файл>] [-lang ru,en] <бот>", runExport, printManifest},
+	"drift":    {"drift [-dir <каталог>]", runDrift, printDrift},
+}
+
+// usageError — ошибка в аргументах командной строки
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("ohana", flag.ContinueOnError)
+	apiID := fs.Int("api-id", envInt("OHANA_API_ID"), "API ID приложения Telegram (OHANA_API_ID)")
+	apiHash := fs.String("api-hash", os.Getenv("OHANA_API_HASH"), "API hash приложения Telegram (OHANA_API_HASH)")
+	phone := fs.String("phone", os.Getenv("OHANA_PHONE"), "номер телефона аккаунта (OHANA_PHONE)")
+	sessionPath := fs.String("session", os.Getenv("OHANA_SESSION"), "путь к файлу сессии (OHANA_SESSION)")
+	output := fs.String("output", "text", "формат результата: text или json (один JSON-документ в stdout)")
+	logLevel := fs.String("log-level", "warn", "уровень логов в stderr: debug, info, warn, error или off")
+	tokenDir := fs.String("token-dir", os.Getenv("OHANA_TOKEN_DIR"), "сохранять токены в <каталог>/<бот>.token (OHANA_TOKEN_DIR)")
+	tokenEnv := fs.String("token-env", os.Getenv("OHANA_TOKEN_ENV"), "сохранять токены в .env-файл (OHANA_TOKEN_ENV)")
+	botAPIURL := fs.String("bot-api-url", os.Getenv("OHANA_BOT_API_URL"), "адрес Bot API (OHANA_BOT_API_URL), по умолчанию "+mahalo.DefaultBotAPIURL)
+	keystore := fs.String("keystore", os.Getenv("OHANA_KEYSTORE"), "сохранять токены в зашифрованное хранилище (OHANA_KEYSTORE, пароль — OHANA_KEYSTORE_PASSPHRASE)")
+	messageInterval := fs.Duration("message-interval", envDuration("OHANA_MESSAGE_INTERVAL", mahalo.DefaultMessageInterval), "минимальный интервал между сообщениями BotFather (OHANA_MESSAGE_INTERVAL)")
+	maxWait := fs.Duration("max-wait", envDuration("OHANA_MAX_WAIT", mahalo.DefaultMaxWait), "самое долгое ожидание по FLOOD_WAIT или просьбе BotFather (OHANA_MAX_WAIT), 0 — без ограничения")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Использование: ohana [флаги] <команда> [аргументы]\n\nКоманды:")
+		for _, name := range []string{"login", "create", "set", "commands", "webhook", "rights", "delete", "list", "token", "revoke", "apply", "export", "drift"} {
+			fmt.Fprintf(fs.Output(), "  %s\n", commands[name].usage)
+		}
+		fmt.Fprintln(fs.Output(), "\nФлаги:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "ohana: неизвестный формат -output %q\n", *output)
+		return exitUsage
+	}
+
+	logger, err := newLogger(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ohana: %v\n", err)
+		return exitUsage
+	}
+
+	sink, err := newTokenSink(*tokenDir, *tokenEnv, *keystore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ohana: %v\n", err)
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ohana: неизвестная команда %q\n", name)
+		fs.Usage()
+		return exitUsage
+	}
+
+	res := ohana.NewResult(name)
+	switch {
+	case *apiID == 0 || *apiHash == "" || *phone == "":
+		err = fmt.Errorf("%w: нужны -api-id, -api-hash и -phone (или OHANA_API_ID, OHANA_API_HASH, OHANA_PHONE)", ohana.ErrNotConfigured)
+	default:
+		opts := []ohana.Option{ohana.WithLogger(logger), ohana.WithBotAPI(*botAPIURL, nil), ohana.WithRateLimit(*messageInterval, 1), ohana.WithMaxWait(*maxWait)}
+		if sink != nil {
+			opts = append(opts, ohana.WithTokenSink(sink))
+		}
+		err = ohana.SetupConfig(*apiID, *apiHash, *phone, *sessionPath, opts...)
+	}
+	if err == nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = cmd.run(ctx, fs.Args()[1:], res)
+	}
+	res.Finish(err)
+	// Если хранилище не приняло токен, он выводится — иначе он будет потерян
+	var (
+		storeErr   *ohana.TokenStoreError
+		unverified *ohana.TokenUnverifiedError
+	)
+	stored := !errors.As(err, &storeErr)
+	if sink != nil && stored {
+		// Токен уже сохранен — в вывод он не попадает
+		hideTokens(res)
+	}
+
+	var ue *usageError
+	if errors.As(err, &ue) {
+		res.ErrorCode = codeUsage
+	}
+
+	switch {
+	case *output == "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(res); encErr != nil {
+			fmt.Fprintf(os.Stderr, "ohana: %v\n", encErr)
+			return exitError
+		}
+	case err == nil || !stored || errors.As(err, &unverified):
+		// Непроверенный токен тоже выдан — результат печатается вместе с ошибкой
+		cmd.text(res)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ohana %s: %v\n", name, err)
+		if ue != nil {
+			fmt.Fprintf(os.Stderr, "использование: ohana %s\n", cmd.usage)
+		}
+	}
+	if report, ok := res.Data.(*ohana.DriftReport); ok && err == nil {
+		return driftExitCode(report)
+	}
+	return exitCode(res.ErrorCode)
+}
+
+// newTokenSink создает TokenSink по флагам; можно задать не больше одного
+func newTokenSink(dir, envPath, keystore string) (ohana.TokenSink, error) {
+	var sinks []ohana.TokenSink
+	if dir != "" {
+		sinks = append(sinks, &ohana.FileTokenSink{Dir: dir})
+	}
+	if envPath != "" {
+		sinks = append(sinks, &ohana.EnvFileTokenSink{Path: envPath})
+	}
+	if keystore != "" {
+		passphrase := os.Getenv("OHANA_KEYSTORE_PASSPHRASE")
+		if passphrase == "" {
+			return nil, fmt.Errorf("для -keystore нужна переменная OHANA_KEYSTORE_PASSPHRASE")
+		}
+		sinks = append(sinks, &ohana.KeystoreTokenSink{Path: keystore, Passphrase: passphrase})
+	}
+
+	switch len(sinks) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sinks[0], nil
+	default:
+		return nil, fmt.Errorf("задайте только один из -token-dir, -token-env и -keystore")
+	}
+}
+
+// hideTokens убирает токены из результата
+func hideTokens(res *ohana.Result) {
+	res.Token = ""
+	if out, ok := res.Data.(*applyOutput); ok && out.Applied != nil {
+		out.Applied.Token = ""
+	}
+}
+
+// newLogger создает текстовый логгер в stderr с уровнем level
+func newLogger(level string) (*slog.Logger, error) {
+	if level == "off" {
+		return slog.New(slog.DiscardHandler), nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("неизвестный уровень -log-level %q", level)
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})), nil
+}
+
+// exitCode выбирает код выхода по коду ошибки результата
+func exitCode(code string) int {
+	switch code {
+	case "":
+		return exitOK
+	case codeUsage:
+		return exitUsage
+	case ohana.CodeCanceled:
+		return exitInterrupted
+	case ohana.CodeNotConfigured, ohana.CodeAuthFailed:
+		return exitAuth
+	case ohana.CodeInvalidUsername, ohana.CodeInvalidCommands, ohana.CodeInvalidManifest, ohana.CodeInvalidToken:
+		return exitInvalid
+	case ohana.CodeBotNotFound:
+		return exitNotFound
+	case ohana.CodeUsernameTaken:
+		return exitTaken
+	case ohana.CodeRateLimited:
+		return exitRateLimited
+	default:
+		return exitError
+	}
+}
+
+// driftExitCode выбирает код выхода по отчету о расхождениях: ошибки важнее расхождений
+func driftExitCode(report *ohana.DriftReport) int {
+	switch {
+	case report.Errors > 0:
+		return exitDriftErrors
+	case report.Drifted > 0:
+		return exitDrift
+	default:
+		return exitOK
+	}
+}
+
+func runLogin(ctx context.Context, args []string, res *ohana.Result) error {
+	if len(args) != 0 {
+		return usagef("лишние аргументы: %v", args)
+	}
+	return ohana.Login(ctx)
+}
+
+func runCreate(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	name := fs.String("name", "", "имя бота")
+	username := fs.String("username", "", "точный username бота")
+	base := fs.String("base", "", "база для автоподбора username")
+	attempts := fs.Int("attempts", 5, "число попыток автоподбора")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	switch {
+	case *name == "":
+		return usagef("не задано -name")
+	case (*username == "") == (*base == ""):
+		return usagef("нужен ровно один из -username и -base")
+	case fs.NArg() != 0:
+		return usagef("лишние аргументы: %v", fs.Args())
+	}
+
+	var err error
+	if *username != "" {
+		res.Username = mahalo.NormalizeBotUsername(*username)
+		res.Token, err = ohana.CreateBotWithUsernameContext(ctx, *name, res.Username)
+	} else {
+		res.Username, res.Token, err = ohana.CreateBotWithAutoUsernameContext(ctx, *name, *base, *attempts)
+	}
+	return err
+}
+
+func runSet(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	token := fs.String("token", os.Getenv("OHANA_BOT_TOKEN"), "токен бота: name, description, about и commands меняются через Bot API (OHANA_BOT_TOKEN)")
+	lang := fs.String("lang", "", "код языка ISO 639-1: задать текст только для пользователей с этим языком")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	args = fs.Args()
+	if len(args) < 3 {
+		return usagef("нужны поле, бот и значение")
+	}
+	field, values := args[0], args[2:]
+	res.Operation += " " + field
+	res.Username = mahalo.NormalizeBotUsername(args[1])
+	if *token != "" {
+		if err := ohana.UseBotToken(res.Username, *token); err != nil {
+			return err
+		}
+	}
+
+	if *lang != "" {
+		return setLocalized(ctx, res.Username, *lang, field, values)
+	}
+
+	switch field {
+	case "name", "description", "about":
+		text := strings.Join(values, " ")
+		setters := map[string]func(context.Context, string, string) error{
+			"name":        ohana.SetBotNameContext,
+			"description": ohana.SetBotDescriptionContext,
+			"about":       ohana.SetBotAboutContext,
+		}
+		return setters[field](ctx, res.Username, text)
+	case "commands":
+		list, err := parseCommands(values)
+		if err != nil {
+			return err
+		}
+		return ohana.SetBotCommandListContext(ctx, res.Username, list)
+	case "userpic":
+		if len(values) != 1 {
+			return usagef("нужен один путь к изображению")
+		}
+		return ohana.SetBotUserpicContext(ctx, res.Username, values[0])
+	default:
+		return usagef("неизвестное поле %q", field)
+	}
+}
+
+// setLocalized задает текст профиля для одного языка
+func setLocalized(ctx context.Context, bot, lang, field string, values []string) error {
+	var p ohana.LocalizedProfile
+	text := strings.Join(values, " ")
+	switch field {
+	case "name":
+		p.Name = text
+	case "description":
+		p.Description = text
+	case "about":
+		p.About = text
+	case "commands":
+		list, err := parseCommands(values)
+		if err != nil {
+			return err
+		}
+		p.Commands = list
+	default:
+		return usagef("поле %q нельзя задать для отдельного языка", field)
+	}
+	return ohana.SetBotLocalized(ctx, bot, lang, p)
+}
+
+// parseCommands разбирает команды: каждая — отдельный аргумент вида "start=Запустить бота"
+func parseCommands(values []string) ([]mahalo.BotCommand, error) {
+	list := make([]mahalo.BotCommand, 0, len(values))
+	for _, v := range values {
+		cmd, desc, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, usagef("команда %q: ожидается формат команда=описание", v)
+		}
+		list = append(list, mahalo.BotCommand{Command: cmd, Description: desc})
+	}
+	return list, nil
+}
+
+// runCommands читает, задает или удаляет команды для одной области видимости и языка
+func runCommands(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("commands", flag.ContinueOnError)
+	token := fs.String("token", os.Getenv("OHANA_BOT_TOKEN"), "токен бота (OHANA_BOT_TOKEN); без него токен запрашивается у BotFather")
+	scopeText := fs.String("scope", mahalo.ScopeDefault, "область: default, all_private_chats, all_group_chats, all_chat_administrators, chat:<id>, chat_administrators:<id>, chat_member:<id>:<user_id>")
+	lang := fs.String("lang", "", "код языка ISO 639-1; по умолчанию — все языки")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	args = fs.Args()
+	if len(args) < 2 {
+		return usagef("нужны действие и бот")
+	}
+	action, values := args[0], args[2:]
+	res.Operation += " " + action
+	res.Username = mahalo.NormalizeBotUsername(args[1])
+	scope, err := mahalo.ParseBotCommandScope(*scopeText)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	if *token != "" {
+		if err := ohana.UseBotToken(res.Username, *token); err != nil {
+			return err
+		}
+	}
+
+	switch action {
+	case "get":
+		if len(values) != 0 {
+			return usagef("лишние аргументы: %v", values)
+		}
+		list, err := ohana.GetCommands(ctx, res.Username, scope, *lang)
+		if list == nil {
+			list = []mahalo.BotCommand{}
+		}
+		res.Data = list
+		return err
+	case "set":
+		list, err := parseCommands(values)
+		if err != nil {
+			return err
+		}
+		return ohana.SetCommands(ctx, res.Username, scope, *lang, list)
+	case "delete":
+		if len(values) != 0 {
+			return usagef("лишние аргументы: %v", values)
+		}
+		return ohana.DeleteCommands(ctx, res.Username, scope, *lang)
+	default:
+		return usagef("неизвестное действие %q: ожидается get, set или delete", action)
+	}
+}
+
+// runWebhook читает, задает или удаляет вебхук бота
+func runWebhook(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("webhook", flag.ContinueOnError)
+	token := fs.String("token", os.Getenv("OHANA_BOT_TOKEN"), "токен бота (OHANA_BOT_TOKEN); без него токен запрашивается у BotFather")
+	secret := fs.String("secret", os.Getenv("OHANA_WEBHOOK_SECRET"), "секрет для заголовка X-Telegram-Bot-Api-Secret-Token (OHANA_WEBHOOK_SECRET)")
+	maxConnections := fs.Int("max-connections", 0, "максимум одновременных соединений, 1–100")
+	allowedUpdates := fs.String("allowed-updates", "", "типы обновлений через запятую, например message,callback_query")
+	dropPending := fs.Bool("drop-pending", false, "при delete — сбросить накопившиеся обновления")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	args = fs.Args()
+	if len(args) < 2 {
+		return usagef("нужны действие и бот")
+	}
+	action, values := args[0], args[2:]
+	res.Operation += " " + action
+	res.Username = mahalo.NormalizeBotUsername(args[1])
+	if *token != "" {
+		if err := ohana.UseBotToken(res.Username, *token); err != nil {
+			return err
+		}
+	}
+
+	switch action {
+	case "get":
+		if len(values) != 0 {
+			return usagef("лишние аргументы: %v", values)
+		}
+		info, err := ohana.GetWebhookInfo(ctx, res.Username)
+		if info != nil {
+			res.Data = info
+		}
+		return err
+	case "set":
+		if len(values) != 1 {
+			return usagef("нужен один адрес вебхука")
+		}
+		w := ohana.Webhook{URL: values[0], SecretToken: *secret, MaxConnections: *maxConnections}
+		for _, u := range strings.Split(*allowedUpdates, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				w.AllowedUpdates = append(w.AllowedUpdates, u)
+			}
+		}
+		return ohana.SetWebhook(ctx, res.Username, w)
+	case "delete":
+		if len(values) != 0 {
+			return usagef("лишние аргументы: %v", values)
+		}
+		return ohana.DeleteWebhook(ctx, res.Username, *dropPending)
+	default:
+		return usagef("неизвестное действие %q: ожидается get, set или delete", action)
+	}
+}
+
+// runRights читает или задает права, предлагаемые при добавлении бота администратором
+func runRights(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("rights", flag.ContinueOnError)
+	token := fs.String("token", os.Getenv("OHANA_BOT_TOKEN"), "токен бота (OHANA_BOT_TOKEN); без него токен запрашивается у BotFather")
+	channels := fs.Bool("channels", false, "права для каналов; по умолчанию — для групп")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	args = fs.Args()
+	if len(args) < 2 {
+		return usagef("нужны действие и бот")
+	}
+	action, values := args[0], args[2:]
+	res.Operation += " " + action
+	res.Username = mahalo.NormalizeBotUsername(args[1])
+	if *token != "" {
+		if err := ohana.UseBotToken(res.Username, *token); err != nil {
+			return err
+		}
+	}
+
+	switch action {
+	case "get":
+		if len(values) != 0 {
+			return usagef("лишние аргументы: %v", values)
+		}
+		rights, err := ohana.GetDefaultAdminRights(ctx, res.Username, *channels)
+		if err == nil {
+			res.Data = rights
+		}
+		return err
+	case "set":
+		// Без прав предложение сбрасывается
+		rights, err := mahalo.ParseAdministratorRights(values)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		return ohana.SetDefaultAdminRights(ctx, res.Username, *channels, rights)
+	default:
+		return usagef("неизвестное действие %q: ожидается get или set", action)
+	}
+}
+
+func runDelete(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "подтвердить удаление")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usagef("нужен один бот")
+	}
+	if !*yes {
+		return usagef("удаление необратимо — подтвердите флагом -yes")
+	}
+	res.Username = mahalo.NormalizeBotUsername(fs.Arg(0))
+	return ohana.DeleteBotContext(ctx, res.Username)
+}
+
+func runList(ctx context.Context, args []string, res *ohana.Result) error {
+	if len(args) != 0 {
+		return usagef("лишние аргументы: %v", args)
+	}
+	bots, err := ohana.ListBots(ctx)
+	if bots == nil {
+		bots = []string{}
+	}
+	res.Data = bots
+	return err
+}
+
+func runToken(ctx context.Context, args []string, res *ohana.Result) error {
+	if len(args) != 1 {
+		return usagef("нужен один бот")
+	}
+	res.Username = mahalo.NormalizeBotUsername(args[0])
+	var err error
+	res.Token, err = ohana.GetBotTokenContext(ctx, res.Username)
+	return err
+}
+
+func runRevoke(ctx context.Context, args []string, res *ohana.Result) error {
+	if len(args) != 1 {
+		return usagef("нужен один бот")
+	}
+	res.Username = mahalo.NormalizeBotUsername(args[0])
+	var err error
+	res.Token, err = ohana.RevokeBotToken(ctx, res.Username)
+	return err
+}
+
+// applyOutput — данные команды apply: план и то, что из него применено
+type applyOutput struct {
+	Plan    *ohana.Plan        `json:"plan"`
+	Applied *ohana.ApplyResult `json:"applied,omitempty"` // nil при -dry-run или без изменений
+}
+
+func runApply(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "только показать изменения")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usagef("нужен один манифест")
+	}
+
+	m, err := ohana.LoadManifest(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	plan, err := ohana.PlanManifest(ctx, m)
+	if err != nil {
+		return err
+	}
+	out := &applyOutput{Plan: plan}
+	res.Data = out
+	res.Username = plan.Username
+	if *dryRun || !plan.HasChanges() {
+		return nil
+	}
+
+	out.Applied, err = ohana.ApplyPlan(ctx, plan)
+	if out.Applied != nil {
+		res.Username, res.Token = out.Applied.Username, out.Applied.Token
+	}
+	return err
+}
+
+func runExport(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("o", "", "файл манифеста (.yaml, .yml или .json); по умолчанию манифест выводится в stdout")
+	langList := fs.String("lang", "", "языки для раздела localized через запятую, например ru,en,kk")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usagef("нужен один бот")
+	}
+	res.Username = mahalo.NormalizeBotUsername(fs.Arg(0))
+	langs, err := ohana.ParseLanguages(*langList)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	if *out != "" {
+		_, err := ohana.ExportBotToFile(ctx, res.Username, *out, langs...)
+		return err
+	}
+	m, err := ohana.ExportBot(ctx, res.Username, langs...)
+	if m != nil {
+		res.Data = m
+	}
+	return err
+}
+
+// ========== ТЕКСТОВЫЙ ВЫВОД ==========
+
+func runDrift(ctx context.Context, args []string, res *ohana.Result) error {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	dir := fs.String("dir", "manifests", "каталог с манифестами ботов")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() != 0 {
+		return usagef("лишние аргументы: %v", fs.Args())
+	}
+
+	report, err := ohana.DetectDrift(ctx, *dir)
+	if report != nil {
+		res.Data = report
+	}
+	return err
+}
+
+func printOK(res *ohana.Result) {
+	fmt.Println("ok")
+}
+
+func printUsernameToken(res *ohana.Result) {
+	fmt.Printf("username: %s\n", res.Username)
+	if res.Token != "" {
+		fmt.Printf("token: %s\n", res.Token)
+	}
+}
+
+// printToken печатает только токен, чтобы его было удобно подставить в скрипт.
+// Если токен сохранен в хранилище, печатается ok.
+func printToken(res *ohana.Result) {
+	if res.Token == "" {
+		printOK(res)
+		return
+	}
+	fmt.Println(res.Token)
+}
+
+func printLines(res *ohana.Result) {
+	for _, line := range res.Data.([]string) {
+		fmt.Println(line)
+	}
+}
+
+// printCommands печатает команды в формате BotFather: "start - Запустить бота"
+func printCommands(res *ohana.Result) {
+	list, ok := res.Data.([]mahalo.BotCommand)
+	if !ok {
+		printOK(res)
+		return
+	}
+	if len(list) > 0 {
+		fmt.Println(mahalo.FormatCommandList(list))
+	}
+}
+
+func printWebhook(res *ohana.Result) {
+	info, ok := res.Data.(*mahalo.WebhookInfo)
+	if !ok {
+		printOK(res)
+		return
+	}
+	if info.URL == "" {
+		fmt.Println("url: нет")
+		return
+	}
+	fmt.Printf("url: %s\n", info.URL)
+	fmt.Printf("pending: %d\n", info.PendingUpdateCount)
+	if info.MaxConnections > 0 {
+		fmt.Printf("max_connections: %d\n", info.MaxConnections)
+	}
+	if len(info.AllowedUpdates) > 0 {
+		fmt.Printf("allowed_updates: %s\n", strings.Join(info.AllowedUpdates, ","))
+	}
+	if info.LastErrorMessage != "" {
+		fmt.Printf("last_error: %s (%s)\n", info.LastErrorMessage, time.Unix(info.LastErrorDate, 0).Format(time.RFC3339))
+	}
+}
+
+func printRights(res *ohana.Result) {
+	rights, ok := res.Data.(mahalo.ChatAdministratorRights)
+	if !ok {
+		printOK(res)
+		return
+	}
+	for _, name := range rights.Enabled() {
+		fmt.Println(name)
+	}
+}
+
+func printApply(res *ohana.Result) {
+	out := res.Data.(*applyOutput)
+	out.Plan.Print(os.Stdout)
+	if a := out.Applied; a != nil {
+		fmt.Printf("username: %s\n", a.Username)
+		if a.Created && a.Token != "" {
+			fmt.Printf("token: %s\n", a.Token)
+		}
+		fmt.Printf("applied: %s\n", strings.Join(a.Applied, " "))
+	}
+}
+
+// printDrift печатает по строке на манифест: = совпадает, ~ расхождения, - бота нет, ! ошибка
+func printDrift(res *ohana.Result) {
+	report := res.Data.(*ohana.DriftReport)
+	for _, e := range report.Bots {
+		switch {
+		case e.Error != "":
+			fmt.Printf("! %s: %s\n", e.Manifest, e.Error)
+		case e.Missing:
+			fmt.Printf("- %s: бота @%s нет\n", e.Manifest, e.Username)
+		case e.Drifted:
+			fmt.Printf("~ %s (@%s): расхождений — %d\n", e.Manifest, e.Username, len(e.Changes))
+			for _, c := range e.Changes {
+				fmt.Printf("  ~ %s: %s → %s\n", c.Field, c.Current, c.Desired)
+			}
+		default:
+			fmt.Printf("= %s (@%s): расхождений нет\n", e.Manifest, e.Username)
+		}
+		for _, field := range e.Unverifiable {
+			fmt.Printf("  ? %s: текущее значение прочитать нельзя\n", field)
+		}
+	}
+	fmt.Printf("проверено: %d, с расхождениями: %d, с ошибками: %d\n", len(report.Bots), report.Drifted, report.Errors)
+}
+
+func printManifest(res *ohana.Result) {
+	if res.Data == nil {
+		printOK(res)
+		return
+	}
+	data, err := yaml.Marshal(res.Data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ohana: %v\n", err)
+		return
+	}
+	os.Stdout.Write(data)
+}
+
+func envInt(name string) int {
+	v, _ := strconv.Atoi(os.Getenv(name))
+	return v
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,122 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/tg"
+)
+
+// DriftReport — результат проверки расхождений для каталога манифестов
+type DriftReport struct {
+	CheckedAt time.Time    `json:"checked_at"`
+	Dir       string       `json:"dir"`
+	Drifted   int          `json:"drifted"` // число ботов с расхождениями или отсутствующих
+	Errors    int          `json:"errors"`  // число манифестов, которые проверить не удалось
+	Bots      []DriftEntry `json:"bots"`
+}
+
+// DriftEntry — результат проверки одного манифеста
+type DriftEntry struct {
+	Manifest     string   `json:"manifest"`
+	Username     string   `json:"username,omitempty"`
+	Missing      bool     `json:"missing,omitempty"` // бота с таким username не существует
+	Drifted      bool     `json:"drifted"`
+	Changes      []Change `json:"changes,omitempty"`
+	Unverifiable []string `json:"unverifiable,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// HasDrift сообщает, найдены ли расхождения или ошибки
+func (r *DriftReport) HasDrift() bool {
+	return r.Drifted > 0 || r.Errors > 0
+}
+
+// DetectDrift читает живое состояние бота для каждого манифеста (.yaml, .yml, .json)
+// в каталоге dir и сравнивает его с манифестом. Все боты проверяются в одном подключении;
+// ошибка одного манифеста попадает в отчет и не прерывает проверку остальных.
+func DetectDrift(ctx context.Context, dir string) (*DriftReport, error) {
+	paths, err := manifestPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = startOperation(ctx, "drift", "")
+	report := &DriftReport{CheckedAt: time.Now().UTC(), Dir: dir}
+	err = withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		for _, path := range paths {
+			entry := checkDrift(ctx, api, botFather, path)
+			if entry.Drifted {
+				report.Drifted++
+			}
+			if entry.Error != "" {
+				report.Errors++
+			}
+			report.Bots = append(report.Bots, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkDrift проверяет один манифест в уже открытом подключении
+func checkDrift(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, path string) DriftEntry {
+	entry := DriftEntry{Manifest: path}
+
+	m, err := LoadManifest(path)
+	if err != nil {
+		entry.Error = mahalo.Redact(err.Error())
+		return entry
+	}
+	if m.Username == "" {
+		entry.Error = "в манифесте нет username — проверять нечего"
+		return entry
+	}
+
+	plan, err := planManifest(ctx, api, botFather, m)
+	if err != nil {
+		entry.Username = m.Username
+		entry.Error = mahalo.Redact(err.Error())
+		return entry
+	}
+
+	entry.Username = plan.Username
+	entry.Missing = plan.Create
+	entry.Changes = plan.Changes
+	// Поля из Reapply сравнить нельзя — для проверки расхождений это непроверяемые поля
+	entry.Unverifiable = slices.Concat(plan.Unverifiable, plan.Reapply)
+	entry.Drifted = plan.Create || len(plan.Changes) > 0
+	return entry
+}
+
+// manifestPaths возвращает файлы манифестов каталога в алфавитном порядке
+func manifestPaths(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог манифестов: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
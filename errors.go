@@ -0,0 +1,116 @@
+package ohana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// Ошибки, которые можно проверить через errors.Is
+var (
+	// ErrNotConfigured — SetupConfig не был вызван
+	ErrNotConfigured = errors.New("конфиг не инициализирован")
+	// ErrAuthFailed — не удалось авторизоваться в Telegram
+	ErrAuthFailed = errors.New("авторизация не удалась")
+)
+
+// InvalidTokenError — токен из ответа BotFather не прошел проверку getMe
+type InvalidTokenError struct {
+	Username string
+	Reason   string
+}
+
+func (e *InvalidTokenError) Error() string {
+	return fmt.Sprintf("токен бота @%s не прошел проверку: %s", e.Username, e.Reason)
+}
+
+// TokenUnverifiedError — токен получен, но getMe был недоступен (сеть, 5xx, 429), и проверить
+// его не удалось. Токен запомнен и передан в TokenSink, бот уже существует — токен
+// возвращается вместе с ошибкой, а проверить его можно позже.
+type TokenUnverifiedError struct {
+	Username string
+	Token    string
+	Err      error
+}
+
+func (e *TokenUnverifiedError) Error() string {
+	return fmt.Sprintf("токен бота @%s не проверен: getMe недоступен: %v", e.Username, e.Err)
+}
+
+func (e *TokenUnverifiedError) Unwrap() error { return e.Err }
+
+// Машиночитаемые коды ошибок для Result.ErrorCode
+const (
+	CodeNotConfigured   = "not_configured"
+	CodeAuthFailed      = "auth_failed"
+	CodeInvalidUsername = "invalid_username"
+	CodeInvalidCommands = "invalid_commands"
+	CodeInvalidManifest = "invalid_manifest"
+	CodeBotNotFound     = "bot_not_found"
+	CodeInvalidToken    = "invalid_token"
+	CodeTokenUnverified = "token_unverified"
+	CodeTokenStore      = "token_store"
+	CodeUsernameTaken   = "username_taken"
+	CodeRateLimited     = "rate_limited"
+	CodeBotAPI          = "bot_api"
+	CodeCanceled        = "canceled"
+	CodeUnknown         = "unknown"
+)
+
+// ErrorCode классифицирует ошибку библиотеки; для nil возвращает ""
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		usernameErr *mahalo.UsernameError
+		commandsErr *mahalo.CommandsError
+		manifestErr *ManifestError
+		notFoundErr *mahalo.BotNotFoundError
+		tokenErr    *InvalidTokenError
+		unverified  *TokenUnverifiedError
+		storeErr    *TokenStoreError
+		botAPIErr   *mahalo.BotAPIError
+		waitErr     *mahalo.WaitTooLongError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, ErrNotConfigured):
+		return CodeNotConfigured
+	case errors.Is(err, ErrAuthFailed):
+		return CodeAuthFailed
+	case errors.As(err, &usernameErr):
+		return CodeInvalidUsername
+	case errors.As(err, &commandsErr):
+		return CodeInvalidCommands
+	case errors.As(err, &manifestErr):
+		return CodeInvalidManifest
+	case errors.As(err, &notFoundErr):
+		return CodeBotNotFound
+	case errors.As(err, &tokenErr):
+		return CodeInvalidToken
+	case errors.As(err, &storeErr):
+		return CodeTokenStore
+	case errors.As(err, &unverified):
+		return CodeTokenUnverified
+	case errors.As(err, &waitErr), errors.As(err, &botAPIErr) && botAPIErr.Code == 429:
+		return CodeRateLimited
+	case errors.As(err, &botAPIErr):
+		return CodeBotAPI
+	}
+
+	// Ошибки BotFather пока приходят текстом
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, mahalo.ErrUsernameTaken):
+		return CodeUsernameTaken
+	case strings.Contains(msg, mahalo.ErrTooManyAttempts), strings.Contains(msg, mahalo.ErrRateLimited):
+		return CodeRateLimited
+	}
+	return CodeUnknown
+}
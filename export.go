@@ -0,0 +1,85 @@
+package ohana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/ghodss/yaml"
+)
+
+// ExportBot читает текущую конфигурацию бота и возвращает ее в виде манифеста.
+// Фото профиля, домен и текст inline-подсказки Bot API не отдает — их нужно дописать вручную.
+// Вебхук не выгружается: секрет Bot API не отдает, и применение такого манифеста стерло бы его.
+// Список языков с собственными текстами Bot API тоже не отдает, поэтому языки для
+// раздела localized передаются в languages.
+func ExportBot(ctx context.Context, botUsername string, languages ...string) (*Manifest, error) {
+	ctx = startOperation(ctx, "export", mahalo.NormalizeBotUsername(botUsername))
+	state, err := ReadBotState(ctx, botUsername, languages...)
+	if err != nil {
+		return nil, err
+	}
+	return state.Manifest(), nil
+}
+
+// ExportBotToFile выгружает конфигурацию бота в файл манифеста (.yaml, .yml или .json)
+func ExportBotToFile(ctx context.Context, botUsername, path string, languages ...string) (*Manifest, error) {
+	m, err := ExportBot(ctx, botUsername, languages...)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveManifest(path, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Manifest превращает прочитанное состояние в манифест
+func (s *BotState) Manifest() *Manifest {
+	privacy, joinGroups := s.Privacy, s.JoinGroups
+	return &Manifest{
+		Name:        s.Name,
+		Username:    s.Username,
+		Description: s.Description,
+		About:       s.About,
+		Commands:    s.Commands,
+		Settings: &BotSettings{
+			Privacy:    &privacy,
+			JoinGroups: &joinGroups,
+		},
+		MenuButton:     s.MenuButton,
+		Localized:      s.Localized,
+		ScopedCommands: s.ScopedCommands,
+		AdminRights:    adminRightsState(s.GroupAdminRights, s.ChannelAdminRights),
+	}
+}
+
+// SaveManifest записывает манифест в YAML или JSON в зависимости от расширения файла
+func SaveManifest(path string, m *Manifest) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err = json.MarshalIndent(m, "", "  ")
+		data = append(data, '\n')
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(m)
+	default:
+		return fmt.Errorf("неизвестный формат манифеста %q: ожидается .yaml, .yml или .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать манифест: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("не удалось записать манифест: %w", err)
+	}
+	return nil
+}
@@ -2,14 +2,16 @@ module github.com/boriuscastus/ohana
 
 go 1.25.5
 
-require github.com/gotd/td v0.136.0
+require (
+	github.com/ghodss/yaml v1.0.0
+	github.com/gotd/td v0.136.0
+)
 
 require (
 	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/coder/websocket v1.8.14 // indirect
 	github.com/dlclark/regexp2 v1.11.5 // indirect
 	github.com/fatih/color v1.18.0 // indirect
-	github.com/ghodss/yaml v1.0.0 // indirect
 	github.com/go-faster/errors v0.7.1 // indirect
 	github.com/go-faster/jx v1.2.0 // indirect
 	github.com/go-faster/xor v1.0.0 // indirect
@@ -0,0 +1,144 @@
+package ohana
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// botAPICall — запрос, дошедший до тестового сервера Bot API
+type botAPICall struct {
+	Method string
+	Params map[string]any
+}
+
+// setupInteractive настраивает библиотеку на p и тестовый сервер Bot API,
+// который принимает любой метод, и возвращает запросы к нему
+func setupInteractive(t *testing.T, p Prompter) func() []botAPICall {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		calls []botAPICall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		mu.Lock()
+		calls = append(calls, botAPICall{Method: r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], Params: params})
+		mu.Unlock()
+		_, _ = io.WriteString(w, `{"ok":true,"result":true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	prev := config
+	t.Cleanup(func() { config = prev })
+	err := SetupConfig(1, "testhash", "+10000000000", filepath.Join(t.TempDir(), "session.json"),
+		WithPrompter(p),
+		WithBotAPI(srv.URL, srv.Client()),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("SetupConfig: %v", err)
+	}
+
+	return func() []botAPICall {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(calls)
+	}
+}
+
+func TestAskBotUsername(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"mybot", "mybot"},
+		{"@mybot", "mybot"},
+		{"  @MyBot  ", "MyBot"},
+		{"https://t.me/mybot/", "mybot"},
+		{"telegram.me/mybot", "mybot"},
+	}
+	for _, tt := range tests {
+		p := &ScriptedPrompter{Answers: []string{tt.answer}}
+		got, err := askBotUsername(p, "бот?")
+		if err != nil {
+			t.Fatalf("askBotUsername(%q): %v", tt.answer, err)
+		}
+		if got != tt.want {
+			t.Errorf("askBotUsername(%q) = %q, ожидалось %q", tt.answer, got, tt.want)
+		}
+		if !slices.Equal(p.Questions, []string{"бот?"}) {
+			t.Errorf("вопросы %q, ожидался один вопрос «бот?»", p.Questions)
+		}
+	}
+
+	if _, err := askBotUsername(&ScriptedPrompter{}, "бот?"); err == nil {
+		t.Error("без ответа askBotUsername должна вернуть ошибку")
+	}
+}
+
+func TestSetTextInteractive(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func() error
+		method  string
+		param   string
+		answers []string
+	}{
+		{"name", SetBotNameInteractive, "setMyName", "name", []string{"@namebot", "Новое имя"}},
+		{"description", SetBotDescriptionInteractive, "setMyDescription", "description", []string{"https://t.me/descbot", "Описание: с двоеточием"}},
+		{"about", SetBotAboutInteractive, "setMyShortDescription", "short_description", []string{"aboutbot", "Коротко о боте"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ScriptedPrompter{Answers: slices.Clone(tt.answers)}
+			calls := setupInteractive(t, p)
+			bot := strings.TrimPrefix(strings.TrimPrefix(tt.answers[0], "https://t.me/"), "@")
+			// С известным токеном настройка уходит в Bot API, без подключения к Telegram
+			if err := UseBotToken(bot, "123456:test-secret"); err != nil {
+				t.Fatalf("UseBotToken: %v", err)
+			}
+			t.Cleanup(func() { forgetToken(bot) })
+
+			if err := tt.run(); err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+
+			got := calls()
+			if len(got) != 1 || got[0].Method != tt.method {
+				t.Fatalf("запросы к Bot API %+v, ожидался один %s", got, tt.method)
+			}
+			if text := got[0].Params[tt.param]; text != tt.answers[1] {
+				t.Errorf("%s = %q, ожидалось %q", tt.param, text, tt.answers[1])
+			}
+			if len(p.Questions) != 2 {
+				t.Errorf("вопросы %q, ожидалось два: username и текст", p.Questions)
+			}
+			want := "✅ Операция успешно выполнена для бота @" + bot
+			if !slices.Contains(p.Messages, want) {
+				t.Errorf("сообщения %q, ожидалось %q", p.Messages, want)
+			}
+		})
+	}
+}
+
+func TestSetTextInteractiveNoAnswer(t *testing.T) {
+	// Ответ только на первый вопрос: до Bot API дело дойти не должно
+	p := &ScriptedPrompter{Answers: []string{"mybot"}}
+	calls := setupInteractive(t, p)
+
+	if err := SetBotNameInteractive(); err == nil {
+		t.Fatal("без текста ожидалась ошибка")
+	}
+	if got := calls(); len(got) != 0 {
+		t.Errorf("запросы к Bot API %+v, ожидалось ни одного", got)
+	}
+}
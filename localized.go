@@ -0,0 +1,169 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// LocalizedProfile — тексты профиля бота для одного языка. Пустые поля не трогаются.
+// Пользователи с этим языком интерфейса видят эти тексты вместо текстов по умолчанию.
+type LocalizedProfile struct {
+	Name        string              `json:"name,omitempty"`
+	Description string              `json:"description,omitempty"`
+	About       string              `json:"about,omitempty"`
+	Commands    []mahalo.BotCommand `json:"commands,omitempty"`
+}
+
+// IsZero сообщает, что ни одно поле не задано
+func (p LocalizedProfile) IsZero() bool {
+	return p.Name == "" && p.Description == "" && p.About == "" && len(p.Commands) == 0
+}
+
+// ValidateLanguageCode проверяет код языка: две строчные латинские буквы ISO 639-1, например "ru"
+func ValidateLanguageCode(code string) error {
+	if len(code) != 2 || code[0] < 'a' || code[0] > 'z' || code[1] < 'a' || code[1] > 'z' {
+		return fmt.Errorf("некорректный код языка %q: ожидаются две строчные латинские буквы ISO 639-1", code)
+	}
+	return nil
+}
+
+// SetBotLocalized задает тексты профиля для языка languageCode через Bot API.
+// Если токен бота неизвестен, он запрашивается у BotFather через /token.
+func SetBotLocalized(ctx context.Context, botUsername, languageCode string, p LocalizedProfile) error {
+	if problems := p.validate(languageCode); len(problems) > 0 {
+		return &ManifestError{Problems: problems}
+	}
+
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "set_localized", botUsername)
+	// Подключение к Telegram нужно, только если токен неизвестен
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		for _, f := range p.settings(languageCode) {
+			if err := f.setting.botAPI(ctx, bot, languageCode); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// localizedSetting — поле профиля для одного языка с именем поля манифеста
+type localizedSetting struct {
+	name    string
+	setting setting
+}
+
+// fields возвращает заданные поля как поля манифеста localized.<lang>.<поле>
+func (p LocalizedProfile) fields(lang string) []manifestField {
+	var fields []manifestField
+	for _, f := range p.settings(lang) {
+		fields = append(fields, manifestField{name: f.name, apply: f.setting.apply})
+	}
+	return fields
+}
+
+// settings возвращает заданные поля как настройки для языка lang
+func (p LocalizedProfile) settings(lang string) []localizedSetting {
+	var settings []localizedSetting
+	add := func(name string, s setting) {
+		settings = append(settings, localizedSetting{name: localizedField(lang, name), setting: s.inLanguage(lang)})
+	}
+
+	if p.Name != "" {
+		add("name", nameSetting(p.Name))
+	}
+	if p.Description != "" {
+		add("description", descriptionSetting(p.Description))
+	}
+	if p.About != "" {
+		add("about", aboutSetting(p.About))
+	}
+	if len(p.Commands) > 0 {
+		add("commands", commandsSetting(p.Commands))
+	}
+	return settings
+}
+
+// validate проверяет код языка и длины текстов, как для текстов по умолчанию
+func (p LocalizedProfile) validate(lang string) []string {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("localized.%s.", lang)+fmt.Sprintf(format, args...))
+	}
+
+	if err := ValidateLanguageCode(lang); err != nil {
+		problems = append(problems, "localized: "+err.Error())
+	}
+	if p.IsZero() {
+		problems = append(problems, fmt.Sprintf("localized.%s: не задано ни одного поля", lang))
+	}
+	if n := utf8.RuneCountInString(p.Name); n > MaxBotNameLength {
+		addf("name: длина %d, максимум %d символов", n, MaxBotNameLength)
+	}
+	if n := utf8.RuneCountInString(p.Description); n > MaxBotDescriptionLength {
+		addf("description: длина %d, максимум %d символов", n, MaxBotDescriptionLength)
+	}
+	if n := utf8.RuneCountInString(p.About); n > MaxBotAboutLength {
+		addf("about: длина %d, максимум %d символов", n, MaxBotAboutLength)
+	}
+	if len(p.Commands) > 0 {
+		if err := mahalo.ValidateCommandList(p.Commands); err != nil {
+			addf("commands: %v", err)
+		}
+	}
+	return problems
+}
+
+// readLocalized читает тексты профиля для языка lang
+func readLocalized(ctx context.Context, bot *mahalo.BotAPI, lang string) (LocalizedProfile, error) {
+	var p LocalizedProfile
+	var err error
+	if p.Name, err = bot.GetMyName(ctx, lang); err != nil {
+		return p, err
+	}
+	if p.Description, err = bot.GetMyDescription(ctx, lang); err != nil {
+		return p, err
+	}
+	if p.About, err = bot.GetMyShortDescription(ctx, lang); err != nil {
+		return p, err
+	}
+	if p.Commands, err = bot.GetMyCommands(ctx, lang); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// languages возвращает языки из манифеста в стабильном порядке
+func (m *Manifest) languages() []string {
+	langs := make([]string, 0, len(m.Localized))
+	for lang := range m.Localized {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func localizedField(lang, field string) string {
+	return "localized." + lang + "." + field
+}
+
+// ParseLanguages разбирает список кодов языков через запятую: "ru,en,kk"
+func ParseLanguages(list string) ([]string, error) {
+	var langs []string
+	for _, lang := range strings.Split(list, ",") {
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		if err := ValidateLanguageCode(lang); err != nil {
+			return nil, err
+		}
+		langs = append(langs, lang)
+	}
+	return langs, nil
+}
@@ -0,0 +1,334 @@
+package mahalo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBotAPIURL — адрес Bot API по умолчанию
+const DefaultBotAPIURL = "https://api.telegram.org"
+
+// BotAPI — минимальный HTTP-клиент Bot API для бота с известным токеном
+type BotAPI struct {
+	BaseURL    string // без завершающего '/', по умолчанию DefaultBotAPIURL
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewBotAPI создает клиент Bot API с адресом по умолчанию
+func NewBotAPI(token string) *BotAPI {
+	return &BotAPI{
+		BaseURL:    DefaultBotAPIURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// BotAPIError — ошибка, которую вернул Bot API
+type BotAPIError struct {
+	Method      string
+	Code        int
+	Description string
+	RetryAfter  int // секунды, если Bot API просит подождать
+}
+
+func (e *BotAPIError) Error() string {
+	return fmt.Sprintf("Bot API %s: %d %s", e.Method, e.Code, e.Description)
+}
+
+// BotUser — ответ getMe
+type BotUser struct {
+	ID                      int64  `json:"id"`
+	IsBot                   bool   `json:"is_bot"`
+	FirstName               string `json:"first_name"`
+	Username                string `json:"username"`
+	CanJoinGroups           bool   `json:"can_join_groups"`
+	CanReadAllGroupMessages bool   `json:"can_read_all_group_messages"`
+	SupportsInlineQueries   bool   `json:"supports_inline_queries"`
+}
+
+// MenuButtonInfo — ответ getChatMenuButton
+type MenuButtonInfo struct {
+	Type   string `json:"type"` // "commands", "web_app" или "default"
+	Text   string `json:"text,omitempty"`
+	WebApp *struct {
+		URL string `json:"url"`
+	} `json:"web_app,omitempty"`
+}
+
+// WebhookParams — параметры setWebhook
+type WebhookParams struct {
+	URL                string   `json:"url"`
+	SecretToken        string   `json:"secret_token,omitempty"`    // приходит в заголовке X-Telegram-Bot-Api-Secret-Token
+	MaxConnections     int      `json:"max_connections,omitempty"` // 1–100, по умолчанию 40
+	AllowedUpdates     []string `json:"allowed_updates,omitempty"` // nil — оставить прежний список
+	IPAddress          string   `json:"ip_address,omitempty"`
+	DropPendingUpdates bool     `json:"drop_pending_updates,omitempty"`
+}
+
+// WebhookInfo — ответ getWebhookInfo. Секрет Bot API не возвращает.
+type WebhookInfo struct {
+	URL                          string   `json:"url"`
+	HasCustomCertificate         bool     `json:"has_custom_certificate"`
+	PendingUpdateCount           int      `json:"pending_update_count"`
+	IPAddress                    string   `json:"ip_address,omitempty"`
+	LastErrorDate                int64    `json:"last_error_date,omitempty"`
+	LastErrorMessage             string   `json:"last_error_message,omitempty"`
+	LastSynchronizationErrorDate int64    `json:"last_synchronization_error_date,omitempty"`
+	MaxConnections               int      `json:"max_connections,omitempty"`
+	AllowedUpdates               []string `json:"allowed_updates,omitempty"`
+}
+
+type botAPIResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// Call вызывает метод Bot API с параметрами params и раскладывает результат в result
+func (b *BotAPI) Call(ctx context.Context, method string, params any, result any) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("Bot API %s: %w", method, err)
+	}
+
+	base := b.BaseURL
+	if base == "" {
+		base = DefaultBotAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(base, "/")+"/bot"+b.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Bot API %s: %w", method, stripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := b.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// В URL запроса есть токен — не пускаем его в текст ошибки
+		return fmt.Errorf("Bot API %s: %w", method, stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	var r botAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("Bot API %s: некорректный ответ (HTTP %d): %w", method, resp.StatusCode, err)
+	}
+	if !r.OK {
+		apiErr := &BotAPIError{Method: method, Code: r.ErrorCode, Description: r.Description}
+		if r.Parameters != nil {
+			apiErr.RetryAfter = r.Parameters.RetryAfter
+		}
+		return apiErr
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("Bot API %s: не удалось разобрать результат: %w", method, err)
+	}
+	return nil
+}
+
+// stripURL убирает из ошибки net/http адрес запроса, содержащий токен
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// GetMe возвращает информацию о боте
+func (b *BotAPI) GetMe(ctx context.Context) (*BotUser, error) {
+	var u BotUser
+	if err := b.Call(ctx, "getMe", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetMyName возвращает имя бота для языка languageCode ("" — язык по умолчанию)
+func (b *BotAPI) GetMyName(ctx context.Context, languageCode string) (string, error) {
+	var r struct {
+		Name string `json:"name"`
+	}
+	err := b.Call(ctx, "getMyName", languageParams(languageCode), &r)
+	return r.Name, err
+}
+
+// GetMyDescription возвращает описание бота (текст в пустом чате)
+func (b *BotAPI) GetMyDescription(ctx context.Context, languageCode string) (string, error) {
+	var r struct {
+		Description string `json:"description"`
+	}
+	err := b.Call(ctx, "getMyDescription", languageParams(languageCode), &r)
+	return r.Description, err
+}
+
+// GetMyShortDescription возвращает короткое описание бота (раздел «О боте»)
+func (b *BotAPI) GetMyShortDescription(ctx context.Context, languageCode string) (string, error) {
+	var r struct {
+		ShortDescription string `json:"short_description"`
+	}
+	err := b.Call(ctx, "getMyShortDescription", languageParams(languageCode), &r)
+	return r.ShortDescription, err
+}
+
+// GetMyCommands возвращает команды бота для области видимости по умолчанию
+func (b *BotAPI) GetMyCommands(ctx context.Context, languageCode string) ([]BotCommand, error) {
+	return b.GetMyCommandsInScope(ctx, DefaultScope(), languageCode)
+}
+
+// GetMyCommandsInScope возвращает команды бота для области scope и языка languageCode
+func (b *BotAPI) GetMyCommandsInScope(ctx context.Context, scope BotCommandScope, languageCode string) ([]BotCommand, error) {
+	var commands []BotCommand
+	err := b.Call(ctx, "getMyCommands", scopeParams(scope, languageCode), &commands)
+	return commands, err
+}
+
+// GetChatMenuButton возвращает кнопку меню по умолчанию
+func (b *BotAPI) GetChatMenuButton(ctx context.Context) (*MenuButtonInfo, error) {
+	var button MenuButtonInfo
+	if err := b.Call(ctx, "getChatMenuButton", nil, &button); err != nil {
+		return nil, err
+	}
+	return &button, nil
+}
+
+// CountProfilePhotos возвращает число фото профиля пользователя userID
+func (b *BotAPI) CountProfilePhotos(ctx context.Context, userID int64) (int, error) {
+	var r struct {
+		TotalCount int `json:"total_count"`
+	}
+	err := b.Call(ctx, "getUserProfilePhotos", map[string]any{"user_id": userID, "limit": 1}, &r)
+	return r.TotalCount, err
+}
+
+// SetMyName задает имя бота для языка languageCode ("" — язык по умолчанию)
+func (b *BotAPI) SetMyName(ctx context.Context, name, languageCode string) error {
+	params := languageParams(languageCode)
+	params["name"] = name
+	return b.Call(ctx, "setMyName", params, nil)
+}
+
+// SetMyDescription задает описание бота (текст в пустом чате)
+func (b *BotAPI) SetMyDescription(ctx context.Context, description, languageCode string) error {
+	params := languageParams(languageCode)
+	params["description"] = description
+	return b.Call(ctx, "setMyDescription", params, nil)
+}
+
+// SetMyShortDescription задает короткое описание бота (раздел «О боте»)
+func (b *BotAPI) SetMyShortDescription(ctx context.Context, shortDescription, languageCode string) error {
+	params := languageParams(languageCode)
+	params["short_description"] = shortDescription
+	return b.Call(ctx, "setMyShortDescription", params, nil)
+}
+
+// SetMyCommands задает команды бота для области видимости по умолчанию
+func (b *BotAPI) SetMyCommands(ctx context.Context, commands []BotCommand, languageCode string) error {
+	return b.SetMyCommandsInScope(ctx, commands, DefaultScope(), languageCode)
+}
+
+// SetMyCommandsInScope задает команды бота для области scope и языка languageCode
+func (b *BotAPI) SetMyCommandsInScope(ctx context.Context, commands []BotCommand, scope BotCommandScope, languageCode string) error {
+	// Как и в FormatCommandList, команды отправляются без ведущего '/'
+	list := make([]BotCommand, len(commands))
+	for i, c := range commands {
+		list[i] = BotCommand{
+			Command:     strings.TrimPrefix(strings.TrimSpace(c.Command), "/"),
+			Description: strings.TrimSpace(c.Description),
+		}
+	}
+
+	params := scopeParams(scope, languageCode)
+	params["commands"] = list
+	return b.Call(ctx, "setMyCommands", params, nil)
+}
+
+// DeleteMyCommands удаляет команды бота для области scope и языка languageCode;
+// пользователи увидят команды более широкой области
+func (b *BotAPI) DeleteMyCommands(ctx context.Context, scope BotCommandScope, languageCode string) error {
+	return b.Call(ctx, "deleteMyCommands", scopeParams(scope, languageCode), nil)
+}
+
+// SetWebhook задает адрес, на который Telegram будет присылать обновления
+func (b *BotAPI) SetWebhook(ctx context.Context, params WebhookParams) error {
+	return b.Call(ctx, "setWebhook", params, nil)
+}
+
+// GetWebhookInfo возвращает текущие настройки вебхука; пустой URL — вебхук не задан
+func (b *BotAPI) GetWebhookInfo(ctx context.Context) (*WebhookInfo, error) {
+	var info WebhookInfo
+	if err := b.Call(ctx, "getWebhookInfo", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// DeleteWebhook удаляет вебхук; dropPendingUpdates — сбросить накопившиеся обновления
+func (b *BotAPI) DeleteWebhook(ctx context.Context, dropPendingUpdates bool) error {
+	params := map[string]any{}
+	if dropPendingUpdates {
+		params["drop_pending_updates"] = true
+	}
+	return b.Call(ctx, "deleteWebhook", params, nil)
+}
+
+// GetMyDefaultAdministratorRights возвращает права, которые Telegram предлагает выдать боту
+// при добавлении в группу (forChannels=false) или в канал (forChannels=true)
+func (b *BotAPI) GetMyDefaultAdministratorRights(ctx context.Context, forChannels bool) (ChatAdministratorRights, error) {
+	var rights ChatAdministratorRights
+	err := b.Call(ctx, "getMyDefaultAdministratorRights", map[string]any{"for_channels": forChannels}, &rights)
+	return rights, err
+}
+
+// SetMyDefaultAdministratorRights задает права, предлагаемые при добавлении бота администратором.
+// Пустые права сбрасывают настройку.
+func (b *BotAPI) SetMyDefaultAdministratorRights(ctx context.Context, rights ChatAdministratorRights, forChannels bool) error {
+	params := map[string]any{"for_channels": forChannels}
+	if !rights.IsZero() {
+		params["rights"] = rights
+	}
+	return b.Call(ctx, "setMyDefaultAdministratorRights", params, nil)
+}
+
+func languageParams(languageCode string) map[string]string {
+	params := map[string]string{}
+	if languageCode != "" {
+		params["language_code"] = languageCode
+	}
+	return params
+}
+
+// scopeParams — параметры методов команд; область по умолчанию не передается
+func scopeParams(scope BotCommandScope, languageCode string) map[string]any {
+	params := map[string]any{}
+	if !scope.IsDefault() {
+		params["scope"] = scope
+	}
+	if languageCode != "" {
+		params["language_code"] = languageCode
+	}
+	return params
+}
@@ -0,0 +1,94 @@
+package mahalo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testToken = "123456:secret-part"
+
+func TestBotAPICall(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    bool
+		wantCode   int
+		wantRetry  int
+		wantResult string
+	}{
+		{"успех", 200, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"mybot"}}`, false, 0, 0, "mybot"},
+		{"неверный токен", 401, `{"ok":false,"error_code":401,"description":"Unauthorized"}`, true, 401, 0, ""},
+		{"бот не найден", 404, `{"ok":false,"error_code":404,"description":"Not Found"}`, true, 404, 0, ""},
+		{"слишком часто", 429, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`, true, 429, 7, ""},
+		{"не JSON", 502, `<html>Bad Gateway</html>`, true, 0, 0, ""},
+		{"некорректный результат", 200, `{"ok":true,"result":"строка вместо объекта"}`, true, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/bot" + testToken + "/getMe"; r.URL.Path != want {
+					t.Errorf("путь %q, ожидался %q", r.URL.Path, want)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			bot := &BotAPI{BaseURL: srv.URL + "/", Token: testToken, HTTPClient: srv.Client()}
+			me, err := bot.GetMe(context.Background())
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("GetMe: %v", err)
+				}
+				if me.Username != tt.wantResult || !me.IsBot {
+					t.Errorf("getMe = %+v", me)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ожидалась ошибка")
+			}
+			var apiErr *BotAPIError
+			gotCode := 0
+			if errors.As(err, &apiErr) {
+				gotCode = apiErr.Code
+				if apiErr.Method != "getMe" || apiErr.RetryAfter != tt.wantRetry {
+					t.Errorf("ошибка %+v, ожидались метод getMe и retry_after %d", apiErr, tt.wantRetry)
+				}
+			}
+			if gotCode != tt.wantCode {
+				t.Errorf("код %d, ожидался %d (ошибка %v)", gotCode, tt.wantCode, err)
+			}
+			if strings.Contains(err.Error(), "secret-part") {
+				t.Errorf("токен попал в текст ошибки: %v", err)
+			}
+		})
+	}
+}
+
+func TestBotAPITransportErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	bot := &BotAPI{BaseURL: url, Token: testToken, HTTPClient: http.DefaultClient}
+	_, err := bot.GetMe(context.Background())
+	if err == nil {
+		t.Fatal("ожидалась ошибка соединения")
+	}
+	var apiErr *BotAPIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("ошибка соединения не должна быть BotAPIError: %v", err)
+	}
+	if strings.Contains(err.Error(), "secret-part") {
+		t.Errorf("токен попал в текст ошибки: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package mahalo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// DialogueStep — один шаг диалога с BotFather: дождаться запроса с одним из
+// ключевых слов Wait и ответить текстом Send
+type DialogueStep struct {
+	Wait []string
+	Send string
+}
+
+// BotNotFoundError — бота с таким username нет среди ботов аккаунта или в Telegram
+type BotNotFoundError struct {
+	Username string
+}
+
+func (e *BotNotFoundError) Error() string {
+	return fmt.Sprintf("бот @%s не найден", e.Username)
+}
+
+// SelectBot отправляет команду BotFather и выбирает в ответ бота @botUsername
+func SelectBot(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, command, botUsername string) error {
+	// 1. Отправляем команду
+	if err := SendMessageWithRetry(ctx, api, botFather, command, 3); err != nil {
+		return err
+	}
+
+	// 2. Ждем выбор бота
+	resp, err := WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"choose a bot", "select a bot", "which bot"},
+		30*time.Second)
+	if err != nil {
+		return fmt.Errorf("ожидание выбора бота: %w", err)
+	}
+
+	if strings.Contains(strings.ToLower(resp), "not found") ||
+		strings.Contains(strings.ToLower(resp), "no bot") {
+		return &BotNotFoundError{Username: botUsername}
+	}
+
+	// 3. Отправляем username бота
+	if err := SendMessageWithRetry(ctx, api, botFather, "@"+botUsername, 3); err != nil {
+		return fmt.Errorf("не удалось отправить username бота: %w", err)
+	}
+
+	return nil
+}
+
+// RunBotCommand выполняет команду BotFather для бота @botUsername:
+// выбирает бота, проходит шаги steps и ждёт подтверждение с successKeywords
+func RunBotCommand(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername, command string, steps []DialogueStep, successKeywords []string) error {
+	if err := SelectBot(ctx, api, botFather, command, botUsername); err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		Logger(ctx).Debug("шаг диалога", "command", command, "step", i+1)
+		if _, err := WaitForResponseWithChecks(ctx, api, botFather, step.Wait, 30*time.Second); err != nil {
+			return fmt.Errorf("ожидание запроса: %w", err)
+		}
+		if err := SendMessageWithRetry(ctx, api, botFather, step.Send, 3); err != nil {
+			return fmt.Errorf("не удалось отправить текст: %w", err)
+		}
+	}
+
+	if _, err := WaitForResponseWithChecks(ctx, api, botFather, successKeywords, 30*time.Second); err != nil {
+		return fmt.Errorf("ожидание подтверждения: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveBot находит бота по username. Если имя никем не занято, возвращает nil без ошибки;
+// если занято не ботом — ошибку.
+func ResolveBot(ctx context.Context, api *tg.Client, username string) (*tg.User, error) {
+	resolved, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
+		Username: username,
+	})
+	if tgerr.Is(err, "USERNAME_NOT_OCCUPIED") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось найти @%s: %w", username, err)
+	}
+
+	for _, user := range resolved.Users {
+		if u, ok := user.(*tg.User); ok && strings.EqualFold(u.Username, username) {
+			if !u.Bot {
+				return nil, fmt.Errorf("@%s не является ботом", username)
+			}
+			Logger(ctx).Debug("бот найден", "bot", u.Username, "user_id", u.ID)
+			return u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("@%s занят, но это не бот", username)
+}
+
+// OwnedBots возвращает ботов, которыми владеет текущий аккаунт
+func OwnedBots(ctx context.Context, api *tg.Client) ([]*tg.User, error) {
+	users, err := api.BotsGetAdminedBots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить список ботов аккаунта: %w", err)
+	}
+
+	var bots []*tg.User
+	for _, user := range users {
+		if u, ok := user.(*tg.User); ok {
+			bots = append(bots, u)
+		}
+	}
+	return bots, nil
+}
@@ -0,0 +1,184 @@
+package mahalo
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsernameCandidate — вариант username, разбитый на части.
+// При сборке обрезается только Stem, так что префикс окружения, дата
+// и суффикс 'bot' всегда сохраняются.
+type UsernameCandidate struct {
+	Prefix string // например "staging_"
+	Stem   string // основа, взятая из базового username
+	Suffix string // всё после основы, включая 'bot'
+}
+
+// Build собирает username, укладываясь в MaxBotUsernameLength. Если префикс и суффикс
+// не оставляют места для основы, возвращается *UsernameError.
+func (c UsernameCandidate) Build() (string, error) {
+	maxStem := MaxBotUsernameLength - len(c.Prefix) - len(c.Suffix)
+	if maxStem < 1 {
+		return "", &UsernameError{Username: c.Prefix + c.Stem + c.Suffix,
+			Reason: fmt.Sprintf("префикс и суффикс длиной %d не оставляют места для основы (максимум — %d)",
+				len(c.Prefix)+len(c.Suffix), MaxBotUsernameLength)}
+	}
+	stem := c.Stem
+	if len(stem) > maxStem {
+		stem = stem[:maxStem]
+	}
+	return c.Prefix + stem + c.Suffix, nil
+}
+
+// String собирает username как Build; если для основы нет места, возвращает
+// вариант без сокращения, который не пройдет ValidateBotUsername
+func (c UsernameCandidate) String() string {
+	username, err := c.Build()
+	if err != nil {
+		return c.Prefix + c.Stem + c.Suffix
+	}
+	return username
+}
+
+// UsernameGenerator выдаёт вариант username для попытки attempt (начиная с 1).
+// base — нормализованный базовый username, с 'bot' на конце или без.
+type UsernameGenerator interface {
+	Candidate(base string, attempt int) UsernameCandidate
+}
+
+// UsernameGeneratorFunc позволяет использовать обычную функцию как UsernameGenerator
+type UsernameGeneratorFunc func(base string, attempt int) UsernameCandidate
+
+func (f UsernameGeneratorFunc) Candidate(base string, attempt int) UsernameCandidate {
+	return f(base, attempt)
+}
+
+// SplitBotSuffix отделяет суффикс 'bot' от основы, сохраняя его регистр.
+// Если суффикса нет, возвращается BotUsernameSuffix.
+func SplitBotSuffix(base string) (stem, suffix string) {
+	n := len(BotUsernameSuffix)
+	if len(base) >= n && strings.EqualFold(base[len(base)-n:], BotUsernameSuffix) {
+		return base[:len(base)-n], base[len(base)-n:]
+	}
+	return base, BotUsernameSuffix
+}
+
+// NumericSuffix вставляет номер попытки перед 'bot': mybot, my2bot, my3bot...
+type NumericSuffix struct{}
+
+func (NumericSuffix) Candidate(base string, attempt int) UsernameCandidate {
+	stem, suffix := SplitBotSuffix(base)
+	if attempt > 1 {
+		suffix = strconv.Itoa(attempt) + suffix
+	}
+	return UsernameCandidate{Stem: stem, Suffix: suffix}
+}
+
+// RandomSuffix вставляет перед 'bot' случайную строку base36 длиной Length
+// (по умолчанию 4), начиная со второй попытки.
+type RandomSuffix struct {
+	Length int
+}
+
+func (g RandomSuffix) Candidate(base string, attempt int) UsernameCandidate {
+	stem, suffix := SplitBotSuffix(base)
+	if attempt == 1 {
+		return UsernameCandidate{Stem: stem, Suffix: suffix}
+	}
+
+	length := g.Length
+	if length <= 0 {
+		length = 4
+	}
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	random := make([]byte, length)
+	for i := range random {
+		random[i] = alphabet[rand.IntN(len(alphabet))]
+	}
+	return UsernameCandidate{Stem: stem, Suffix: string(random) + suffix}
+}
+
+// UnderscoreVariants сначала пробует базу как есть, затем чередует варианты 'bot'
+// и '_bot' с номером, пропуская совпадающий с базой:
+// mybot, my_bot, my2bot, my2_bot, my3bot...; для my_bot — my_bot, mybot, my2bot, my2_bot...
+type UnderscoreVariants struct{}
+
+func (UnderscoreVariants) Candidate(base string, attempt int) UsernameCandidate {
+	stem, suffix := SplitBotSuffix(base)
+	if attempt <= 1 {
+		return UsernameCandidate{Stem: stem, Suffix: suffix}
+	}
+
+	// Номер варианта в последовательности core+bot, core+_bot, core+2bot, core+2_bot...
+	core := strings.TrimRight(stem, "_")
+	variant := attempt - 2
+	switch stem {
+	case core:
+		variant++ // база — вариант 0
+	case core + "_":
+		if variant >= 1 {
+			variant++ // база — вариант 1
+		}
+	}
+
+	number, separator := "", ""
+	if n := variant/2 + 1; n > 1 {
+		number = strconv.Itoa(n)
+	}
+	if variant%2 == 1 {
+		separator = "_"
+	}
+	return UsernameCandidate{Stem: core, Suffix: number + separator + suffix}
+}
+
+// EnvPrefix добавляет префикс окружения (например "staging_") к вариантам Next
+// (по умолчанию NumericSuffix). Префикс не обрезается.
+type EnvPrefix struct {
+	Prefix string
+	Next   UsernameGenerator
+}
+
+func (g EnvPrefix) Candidate(base string, attempt int) UsernameCandidate {
+	c := nextOrDefault(g.Next).Candidate(base, attempt)
+	c.Prefix = g.Prefix + c.Prefix
+	return c
+}
+
+// DateStamp добавляет дату после основы: my_20261019bot, my_20261019_2bot...
+// Номер попытки отделяется от даты '_', чтобы не сливаться с ней. Layout по умолчанию
+// "20060102" (в нём допустимы только буквы, цифры и '_'), Now по умолчанию
+// time.Now, варианты берутся из Next.
+type DateStamp struct {
+	Layout string
+	Next   UsernameGenerator
+	Now    func() time.Time
+}
+
+func (g DateStamp) Candidate(base string, attempt int) UsernameCandidate {
+	layout := g.Layout
+	if layout == "" {
+		layout = "20060102"
+	}
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+
+	c := nextOrDefault(g.Next).Candidate(base, attempt)
+	stamp := "_" + now().Format(layout)
+	if c.Suffix != "" && c.Suffix[0] >= '0' && c.Suffix[0] <= '9' {
+		stamp += "_"
+	}
+	c.Suffix = stamp + c.Suffix
+	return c
+}
+
+func nextOrDefault(g UsernameGenerator) UsernameGenerator {
+	if g == nil {
+		return NumericSuffix{}
+	}
+	return g
+}
@@ -0,0 +1,73 @@
+package mahalo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsernameGenerators(t *testing.T) {
+	date := func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		gen  UsernameGenerator
+		base string
+		want []string // варианты для попыток 1, 2, 3...
+	}{
+		{"NumericSuffix", NumericSuffix{}, "mybot", []string{"mybot", "my2bot", "my3bot"}},
+		{"NumericSuffix без bot", NumericSuffix{}, "shop", []string{"shopbot", "shop2bot", "shop3bot"}},
+		{"NumericSuffix сохраняет регистр", NumericSuffix{}, "ShopBot", []string{"ShopBot", "Shop2Bot"}},
+		{"UnderscoreVariants от mybot", UnderscoreVariants{}, "mybot", []string{"mybot", "my_bot", "my2bot", "my2_bot", "my3bot"}},
+		{"UnderscoreVariants от my_bot", UnderscoreVariants{}, "my_bot", []string{"my_bot", "mybot", "my2bot", "my2_bot", "my3bot"}},
+		{"EnvPrefix", EnvPrefix{Prefix: "staging_"}, "shopbot", []string{"staging_shopbot", "staging_shop2bot"}},
+		{"EnvPrefix с UnderscoreVariants", EnvPrefix{Prefix: "dev_", Next: UnderscoreVariants{}}, "shopbot", []string{"dev_shopbot", "dev_shop_bot"}},
+		{"DateStamp", DateStamp{Now: date}, "my_bot", []string{"my__20261019bot", "my__20261019_2bot", "my__20261019_3bot"}},
+		{"DateStamp без '_' в основе", DateStamp{Now: date}, "mybot", []string{"my_20261019bot", "my_20261019_2bot"}},
+		{"DateStamp со своим layout", DateStamp{Now: date, Layout: "0601"}, "shopbot", []string{"shop_2610bot", "shop_2610_2bot"}},
+		{"DateStamp с UnderscoreVariants", DateStamp{Now: date, Next: UnderscoreVariants{}}, "shopbot", []string{"shop_20261019bot", "shop_20261019_bot"}},
+		{"длинная основа обрезается, префикс и суффикс — нет", EnvPrefix{Prefix: "staging_"}, strings.Repeat("a", 30) + "bot",
+			[]string{"staging_" + strings.Repeat("a", 21) + "bot", "staging_" + strings.Repeat("a", 20) + "2bot"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				got, err := tt.gen.Candidate(tt.base, i+1).Build()
+				if err != nil {
+					t.Fatalf("попытка %d: %v", i+1, err)
+				}
+				if got != want {
+					t.Errorf("попытка %d: %q, ожидалось %q", i+1, got, want)
+				}
+				if err := ValidateBotUsername(got); err != nil {
+					t.Errorf("попытка %d: %v", i+1, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomSuffix(t *testing.T) {
+	gen := RandomSuffix{Length: 6}
+	if got := gen.Candidate("shopbot", 1).String(); got != "shopbot" {
+		t.Errorf("первая попытка %q, ожидалось %q", got, "shopbot")
+	}
+	for attempt := 2; attempt < 10; attempt++ {
+		got := gen.Candidate("shopbot", attempt).String()
+		if len(got) != len("shopbot")+6 || !strings.HasPrefix(got, "shop") || !strings.HasSuffix(got, "bot") {
+			t.Errorf("попытка %d: %q", attempt, got)
+		}
+		if err := ValidateBotUsername(got); err != nil {
+			t.Errorf("попытка %d: %v", attempt, err)
+		}
+	}
+}
+
+func TestUsernameCandidateBuildNoRoom(t *testing.T) {
+	c := UsernameCandidate{Prefix: strings.Repeat("p", 20), Stem: "shop", Suffix: "_" + strings.Repeat("9", 10) + "bot"}
+	var usernameErr *UsernameError
+	if _, err := c.Build(); !errors.As(err, &usernameErr) {
+		t.Errorf("Build = %v, ожидалась *UsernameError", err)
+	}
+}
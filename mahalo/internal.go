@@ -4,14 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/gotd/td/telegram/downloader"
 	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tgerr"
 	"github.com/gotd/td/tg"
 )
 
@@ -26,21 +28,18 @@ func GenerateRandomID() int64 {
 
 // findBotFather находит пользователя BotFather
 func FindBotFather(ctx context.Context, api *tg.Client) (*tg.InputPeerUser, error) {
-	log.Printf("🔍 Ищем BotFather...")
+	logger := Logger(ctx)
+	logger.Debug("ищем BotFather")
 	resolved, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
 		Username: "BotFather",
 	})
 	if err != nil {
-		log.Printf("❌ Ошибка при поиске BotFather: %v", err)
 		return nil, fmt.Errorf("не удалось найти BotFather: %w", err)
 	}
 
-	log.Printf("📋 Найдено пользователей: %d", len(resolved.Users))
 	var botFatherUser *tg.User
-	for i, user := range resolved.Users {
-		log.Printf("  User %d: %T", i, user)
+	for _, user := range resolved.Users {
 		if u, ok := user.(*tg.User); ok {
-			log.Printf("    ID: %d, Username: %s", u.ID, u.Username)
 			if u.Username == "BotFather" {
 				botFatherUser = u
 				break
@@ -49,33 +48,44 @@ func FindBotFather(ctx context.Context, api *tg.Client) (*tg.InputPeerUser, erro
 	}
 
 	if botFatherUser == nil {
-		log.Printf("❌ BotFather не найден в списке пользователей")
 		return nil, fmt.Errorf("BotFather не найден")
 	}
 
-	log.Printf("✅ BotFather найден! ID: %d", botFatherUser.ID)
+	logger.Debug("BotFather найден", "user_id", botFatherUser.ID)
 	return &tg.InputPeerUser{
 		UserID:     botFatherUser.ID,
 		AccessHash: botFatherUser.AccessHash,
 	}, nil
 }
 
-// sendMessage отправляет сообщение
+// sendMessage отправляет сообщение в темпе планировщика из контекста;
+// при FLOOD_WAIT ждет указанное Telegram время и повторяет отправку
 func SendMessage(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, text string) error {
-	_, err := api.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
-		Peer:      peer,
-		Message:   text,
-		RandomID:  GenerateRandomID(),
-		NoWebpage: true,
-	})
+	scheduler := SchedulerFromContext(ctx)
+	for {
+		if err := scheduler.Wait(ctx); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return fmt.Errorf("не удалось отправить сообщение: %w", err)
-	}
+		_, err := api.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+			Peer:      peer,
+			Message:   text,
+			RandomID:  GenerateRandomID(),
+			NoWebpage: true,
+		})
+		if wait, ok := tgerr.AsFloodWait(err); ok {
+			if err := scheduler.Block(wait, WaitFlood); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось отправить сообщение: %w", err)
+		}
 
-	log.Printf("📤 Отправлено: %s", text)
-	time.Sleep(1 * time.Second)
-	return nil
+		Logger(ctx).Debug("отправлено BotFather", "text", text)
+		return nil
+	}
 }
 
 // getLastMessage получает последнее сообщение от собеседника
@@ -94,21 +104,21 @@ func GetLastMessage(ctx context.Context, api *tg.Client, peer tg.InputPeerClass)
 	case *tg.MessagesChannelMessages:
 		if len(h.Messages) > 0 {
 			if msg, ok := h.Messages[0].(*tg.Message); ok && !msg.Out {
-				log.Printf("📥 Получено: %s", msg.Message)
+				Logger(ctx).Debug("получено от BotFather", "text", msg.Message)
 				return msg.Message, nil
 			}
 		}
 	case *tg.MessagesMessages:
 		if len(h.Messages) > 0 {
 			if msg, ok := h.Messages[0].(*tg.Message); ok && !msg.Out {
-				log.Printf("📥 Получено: %s", msg.Message)
+				Logger(ctx).Debug("получено от BotFather", "text", msg.Message)
 				return msg.Message, nil
 			}
 		}
 	case *tg.MessagesMessagesSlice:
 		if len(h.Messages) > 0 {
 			if msg, ok := h.Messages[0].(*tg.Message); ok && !msg.Out {
-				log.Printf("📥 Получено: %s", msg.Message)
+				Logger(ctx).Debug("получено от BotFather", "text", msg.Message)
 				return msg.Message, nil
 			}
 		}
@@ -117,8 +127,43 @@ func GetLastMessage(ctx context.Context, api *tg.Client, peer tg.InputPeerClass)
 	return "", nil
 }
 
+// GetLastMessageButtons возвращает тексты inline-кнопок последнего сообщения собеседника
+func GetLastMessageButtons(ctx context.Context, api *tg.Client, peer tg.InputPeerClass) ([]string, error) {
+	history, err := api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+		Peer:  peer,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить историю: %w", err)
+	}
+
+	modified, ok := history.AsModified()
+	if !ok || len(modified.GetMessages()) == 0 {
+		return nil, nil
+	}
+	msg, ok := modified.GetMessages()[0].(*tg.Message)
+	if !ok || msg.Out {
+		return nil, nil
+	}
+	markup, ok := msg.ReplyMarkup.(*tg.ReplyInlineMarkup)
+	if !ok {
+		return nil, nil
+	}
+
+	var buttons []string
+	for _, row := range markup.Rows {
+		for _, button := range row.Buttons {
+			if b, ok := button.(interface{ GetText() string }); ok {
+				buttons = append(buttons, b.GetText())
+			}
+		}
+	}
+	return buttons, nil
+}
+
 // waitForResponseWithChecks ждет ответ с проверкой ошибок
 func WaitForResponseWithChecks(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, keywords []string, timeout time.Duration) (string, error) {
+	scheduler := SchedulerFromContext(ctx)
 	deadline := time.After(timeout)
 
 	for {
@@ -129,23 +174,36 @@ func WaitForResponseWithChecks(ctx context.Context, api *tg.Client, peer tg.Inpu
 			return "", ctx.Err()
 		default:
 			msg, err := GetLastMessage(ctx, api, peer)
+			if wait, ok := tgerr.AsFloodWait(err); ok {
+				// Чтение истории тоже ограничено — ждем и продолжаем с новым дедлайном
+				if err := scheduler.Block(wait, WaitFlood); err != nil {
+					return "", err
+				}
+				if err := scheduler.Pause(ctx); err != nil {
+					return "", err
+				}
+				deadline = time.After(timeout)
+				continue
+			}
 			if err != nil {
 				return "", err
 			}
 
 			// Проверяем на ошибки BotFather
 			if err := CheckBotFatherError(msg); err != nil {
-				// Если это "too many attempts" — ждём указанное время и повторяем
+				// Если это "too many attempts" — ждём указанное время и повторяем.
+				// Пауза общая для аккаунта: остальные сообщения BotFather тоже подождут.
+				// Слишком долгое ожидание (больше MaxWait) прерывает диалог с *WaitTooLongError.
 				if strings.Contains(err.Error(), ErrTooManyAttempts) {
-					// Извлекаем время ожидания
-					seconds := ExtractWaitTime(msg)
-					if seconds > 0 {
-						log.Printf("⏳ BotFather требует подождать %d сек, ожидаем...", seconds)
-						time.Sleep(time.Duration(seconds) * time.Second)
-						// Сбрасываем дедлайн и повторяем попытку
-						deadline = time.After(timeout)
-						continue
+					if err := scheduler.Block(ExtractWaitTime(msg), WaitBotFather); err != nil {
+						return "", err
 					}
+					if err := scheduler.Pause(ctx); err != nil {
+						return "", err
+					}
+					// Сбрасываем дедлайн и повторяем попытку
+					deadline = time.After(timeout)
+					continue
 				}
 				return "", err
 			}
@@ -210,8 +268,7 @@ func SendPhoto(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, file
 	}
 
 	filename := filepath.Base(filePath)
-	log.Printf("📤 Отправляем фото: %s (%.2f MB)", filename,
-		float64(fileInfo.Size())/1024/1024)
+	Logger(ctx).Debug("отправляем фото", "file", filename, "size", fileInfo.Size())
 
 	// Создаем uploader
 	upd := uploader.NewUploader(api)
@@ -223,19 +280,129 @@ func SendPhoto(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, file
 	}
 
 	// Отправляем как Photo
-	_, err = api.MessagesSendMedia(ctx, &tg.MessagesSendMediaRequest{
-		Peer: peer,
-		Media: &tg.InputMediaUploadedPhoto{
-			File: upload,
-		},
-		Message:  " ",
-		RandomID: GenerateRandomID(),
-	})
+	scheduler := SchedulerFromContext(ctx)
+	for {
+		if err := scheduler.Wait(ctx); err != nil {
+			return err
+		}
+		_, err = api.MessagesSendMedia(ctx, &tg.MessagesSendMediaRequest{
+			Peer: peer,
+			Media: &tg.InputMediaUploadedPhoto{
+				File: upload,
+			},
+			Message:  " ",
+			RandomID: GenerateRandomID(),
+		})
+		if wait, ok := tgerr.AsFloodWait(err); ok {
+			if err := scheduler.Block(wait, WaitFlood); err != nil {
+				return err
+			}
+			continue
+		}
+		break
+	}
 
 	if err != nil {
 		return fmt.Errorf("не удалось отправить фото: %w", err)
 	}
 
-	log.Printf("✅ Фото отправлено")
+	Logger(ctx).Debug("фото отправлено", "file", filename)
 	return nil
 }
+
+// CheckUsernameAvailable проверяет username до обращения к BotFather.
+// true означает «вероятно свободен» — окончательно решает всё равно BotFather,
+// false — имя точно занято (или не может быть получено), и тратить на него попытку незачем.
+// На FLOOD_WAIT проверка ждет через планировщик и повторяется; если ждать дольше MaxWait,
+// проверка пропускается и решение остается за BotFather.
+func CheckUsernameAvailable(ctx context.Context, api *tg.Client, username string) (bool, error) {
+	err := retryOnFloodWait(ctx, func() error {
+		_, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
+			Username: username,
+		})
+		return err
+	})
+	var tooLong *WaitTooLongError
+	switch {
+	case errors.As(err, &tooLong):
+		Logger(ctx).Warn("проверка username пропущена: Telegram просит подождать", "candidate", username, "wait", tooLong.Wait)
+		return true, nil
+	case err == nil:
+		Logger(ctx).Debug("username занят", "candidate", username)
+		return false, nil
+	case tgerr.Is(err, "USERNAME_INVALID"):
+		return false, &UsernameError{Username: username, Reason: "Telegram отклонил username"}
+	case !tgerr.Is(err, "USERNAME_NOT_OCCUPIED"):
+		return false, fmt.Errorf("не удалось проверить username @%s: %w", username, err)
+	}
+
+	// account.checkUsername дополнительно знает о зарезервированных и коллекционных именах.
+	// Для имён на 'bot' он обычно отвечает USERNAME_INVALID — это не признак занятости.
+	// Остальные ошибки (сеть, авторизация) ничего не говорят об имени и возвращаются.
+	var ok bool
+	err = retryOnFloodWait(ctx, func() error {
+		var err error
+		ok, err = api.AccountCheckUsername(ctx, username)
+		return err
+	})
+	switch {
+	case errors.As(err, &tooLong):
+		Logger(ctx).Warn("проверка username пропущена: Telegram просит подождать", "candidate", username, "wait", tooLong.Wait)
+		return true, nil
+	case err == nil:
+	case tgerr.Is(err, "USERNAME_INVALID"):
+		return true, nil
+	case tgerr.Is(err, "USERNAME_OCCUPIED"):
+		Logger(ctx).Debug("username занят", "candidate", username)
+		return false, nil
+	case tgerr.Is(err, "USERNAME_PURCHASE_AVAILABLE"):
+		Logger(ctx).Debug("username продаётся на fragment.com", "candidate", username)
+		return false, nil
+	default:
+		return false, fmt.Errorf("не удалось проверить username @%s: %w", username, err)
+	}
+	if !ok {
+		Logger(ctx).Debug("username недоступен", "candidate", username)
+	}
+	return ok, nil
+}
+
+// retryOnFloodWait вызывает call и на FLOOD_WAIT ставит паузу планировщика, ждет ее
+// и повторяет вызов. Пауза дольше MaxWait возвращается как *WaitTooLongError.
+func retryOnFloodWait(ctx context.Context, call func() error) error {
+	scheduler := SchedulerFromContext(ctx)
+	for {
+		err := call()
+		wait, ok := tgerr.AsFloodWait(err)
+		if !ok {
+			return err
+		}
+		if err := scheduler.Block(wait, WaitFlood); err != nil {
+			return err
+		}
+		if err := scheduler.Pause(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// DownloadProfilePhoto сохраняет текущее фото профиля пользователя в файл path.
+// Возвращает false, если фото нет.
+func DownloadProfilePhoto(ctx context.Context, api *tg.Client, user *tg.User, path string) (bool, error) {
+	photo, ok := user.Photo.(*tg.UserProfilePhoto)
+	if !ok {
+		return false, nil
+	}
+
+	location := &tg.InputPeerPhotoFileLocation{
+		Big:     true,
+		Peer:    &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash},
+		PhotoID: photo.PhotoID,
+	}
+	if _, err := downloader.NewDownloader().Download(api, location).ToPath(ctx, path); err != nil {
+		return false, fmt.Errorf("не удалось скачать фото профиля @%s: %w", user.Username, err)
+	}
+
+	Logger(ctx).Debug("фото профиля сохранено", "path", path)
+	return true, nil
+}
@@ -0,0 +1,34 @@
+package mahalo
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// discardLogger — логгер по умолчанию: библиотека молчит, пока логгер не задан
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// WithLogger возвращает контекст, в котором функции mahalo пишут в logger.
+// Секреты в записях маскируются через Redact.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	if logger != nil {
+		logger = slog.New(NewRedactingHandler(logger.Handler()))
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext возвращает логгер, положенный в контекст через WithLogger
+func LoggerFromContext(ctx context.Context) (*slog.Logger, bool) {
+	logger, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	return logger, ok && logger != nil
+}
+
+// Logger возвращает логгер из контекста или логгер, который ничего не пишет
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := LoggerFromContext(ctx); ok {
+		return logger
+	}
+	return discardLogger
+}
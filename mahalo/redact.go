@@ -0,0 +1,149 @@
+package mahalo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Шаблоны секретов, которые маскируются в логах и текстах ошибок
+var (
+	// Токен бота: id бота, двоеточие и секретная часть — как в ParseToken.
+	// Без \b в начале: в URL Bot API токен идет сразу после "bot"
+	tokenPattern = regexp.MustCompile(`(\d{5,}):[A-Za-z0-9_-]{20,}`)
+	// Номер телефона в международном формате
+	phonePattern = regexp.MustCompile(`\+\d{9,15}\b`)
+	// API hash приложения Telegram
+	apiHashPattern = regexp.MustCompile(`\b[0-9a-fA-F]{32}\b`)
+	// Код входа в сообщениях Telegram: "Login code: 12345"
+	loginCodePattern = regexp.MustCompile(`(?i)(code\W{0,3})\d{5,6}\b`)
+)
+
+// Точные значения секретов: телефон, API hash, секрет вебхука
+var secrets = struct {
+	sync.RWMutex
+	values []string
+}{}
+
+// RegisterSecret добавляет значение, которое Redact всегда заменяет на ***.
+// Пустые и слишком короткие значения игнорируются.
+func RegisterSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < 4 {
+		return
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+	for _, v := range secrets.values {
+		if v == value {
+			return
+		}
+	}
+	secrets.values = append(secrets.values, value)
+	// Длинные значения заменяем первыми, чтобы не оставить хвост от частичной замены
+	sort.Slice(secrets.values, func(i, j int) bool { return len(secrets.values[i]) > len(secrets.values[j]) })
+}
+
+// Redact маскирует в s токены ботов (остается id бота), номера телефонов,
+// API hash, коды входа и значения, зарегистрированные через RegisterSecret
+func Redact(s string) string {
+	// Сначала шаблоны: точное значение внутри токена иначе разорвало бы его,
+	// и секретная часть токена перестала бы совпадать с tokenPattern
+	s = tokenPattern.ReplaceAllString(s, "$1:***")
+	s = phonePattern.ReplaceAllStringFunc(s, func(phone string) string {
+		return "+***" + phone[len(phone)-2:]
+	})
+	s = apiHashPattern.ReplaceAllString(s, "***")
+	s = loginCodePattern.ReplaceAllString(s, "${1}***")
+
+	secrets.RLock()
+	for _, v := range secrets.values {
+		s = strings.ReplaceAll(s, v, "***")
+	}
+	secrets.RUnlock()
+	return s
+}
+
+// RedactError возвращает ошибку с замаскированным текстом; errors.Is и errors.As
+// продолжают работать с исходной ошибкой
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*redactedError); ok {
+		return err
+	}
+	return &redactedError{err: err}
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string { return Redact(e.err.Error()) }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// NewRedactingHandler оборачивает handler так, что сообщение и строковые
+// атрибуты записей проходят через Redact
+func NewRedactingHandler(handler slog.Handler) slog.Handler {
+	if _, ok := handler.(*redactingHandler); ok {
+		return handler
+	}
+	return &redactingHandler{next: handler}
+}
+
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		// Ошибки и прочие значения сравниваем по тексту: маскируем, только если там есть секрет
+		text := fmt.Sprint(a.Value.Any())
+		if redacted := Redact(text); redacted != text {
+			a.Value = slog.StringValue(redacted)
+		}
+	}
+	return a
+}
@@ -0,0 +1,99 @@
+package mahalo
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// withSecrets подменяет зарегистрированные секреты на время теста
+func withSecrets(t *testing.T, values ...string) {
+	t.Helper()
+	secrets.Lock()
+	prev := secrets.values
+	secrets.values = nil
+	secrets.Unlock()
+	t.Cleanup(func() {
+		secrets.Lock()
+		secrets.values = prev
+		secrets.Unlock()
+	})
+	for _, v := range values {
+		RegisterSecret(v)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	const token = "1234567:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw"
+	tests := []struct {
+		name    string
+		secrets []string
+		in      string
+		want    string
+	}{
+		{"токен бота, id остается", nil, "токен " + token + " выдан", "токен 1234567:*** выдан"},
+		{"токен в URL", nil, "https://api.telegram.org/bot" + token + "/getMe", "https://api.telegram.org/bot1234567:***/getMe"},
+		{"короткая секретная часть — не токен", nil, "время 12345:30", "время 12345:30"},
+		{"телефон, остаются две цифры", nil, "вход с +79991234567", "вход с +***67"},
+		{"API hash", nil, "hash 0123456789abcdef0123456789ABCDEF", "hash ***"},
+		{"код входа", nil, "Login code: 12345. Do not give it to anyone", "Login code: ***. Do not give it to anyone"},
+		{"код входа без учета регистра", nil, "CODE 654321", "CODE ***"},
+		{"обычные числа не трогаем", nil, "бот 12345 создан за 123456 мс", "бот 12345 создан за 123456 мс"},
+		{"точное значение", []string{"hook-secret"}, "secret_token=hook-secret", "secret_token=***"},
+		{"короткие значения не регистрируются", []string{"abc"}, "abc", "abc"},
+		{"длинное значение раньше короткого", []string{"pass", "password1"}, "password1 pass", "*** ***"},
+		// Шаблоны до точных значений: секрет внутри токена не должен разорвать токен
+		{"шаблоны раньше точных значений", []string{"CH1vGWJx"}, token, "1234567:***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSecrets(t, tt.secrets...)
+			if got := Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, ожидалось %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	withSecrets(t)
+	cause := &fs.PathError{Op: "open", Path: "/tmp/1234567:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw", Err: fs.ErrNotExist}
+	err := RedactError(cause)
+	if strings.Contains(err.Error(), "AAHdq") {
+		t.Errorf("токен не замаскирован: %v", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("errors.Is не видит исходную ошибку")
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr != cause {
+		t.Error("errors.As не видит исходную ошибку")
+	}
+	if RedactError(err) != err {
+		t.Error("повторная обертка не нужна")
+	}
+	if RedactError(nil) != nil {
+		t.Error("RedactError(nil) должен вернуть nil")
+	}
+}
+
+func TestRedactingHandler(t *testing.T) {
+	withSecrets(t, "hook-secret")
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(slog.NewTextHandler(&buf, nil))).
+		With("phone", "+79991234567")
+	logger.Info("код 12345", "secret", "hook-secret",
+		slog.Group("bot", "token", "1234567:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw"),
+		"err", errors.New("bad hook-secret"))
+
+	out := buf.String()
+	for _, leaked := range []string{"79991234567", "12345 ", "hook-secret", "AAHdq"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("в логе остался секрет %q: %s", leaked, out)
+		}
+	}
+}
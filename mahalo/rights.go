@@ -0,0 +1,87 @@
+package mahalo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChatAdministratorRights — права администратора (ChatAdministratorRights в Bot API).
+// Права для каналов и для групп задаются отдельно; часть полей имеет смысл только для одного из них.
+type ChatAdministratorRights struct {
+	IsAnonymous         bool `json:"is_anonymous"`
+	CanManageChat       bool `json:"can_manage_chat"`
+	CanDeleteMessages   bool `json:"can_delete_messages"`
+	CanManageVideoChats bool `json:"can_manage_video_chats"`
+	CanRestrictMembers  bool `json:"can_restrict_members"`
+	CanPromoteMembers   bool `json:"can_promote_members"`
+	CanChangeInfo       bool `json:"can_change_info"`
+	CanInviteUsers      bool `json:"can_invite_users"`
+	CanPostStories      bool `json:"can_post_stories"`
+	CanEditStories      bool `json:"can_edit_stories"`
+	CanDeleteStories    bool `json:"can_delete_stories"`
+	CanPostMessages     bool `json:"can_post_messages,omitempty"` // только каналы
+	CanEditMessages     bool `json:"can_edit_messages,omitempty"` // только каналы
+	CanPinMessages      bool `json:"can_pin_messages,omitempty"`  // только группы
+	CanManageTopics     bool `json:"can_manage_topics,omitempty"` // только супергруппы
+}
+
+// fields возвращает права по именам из Bot API
+func (r *ChatAdministratorRights) fields() map[string]*bool {
+	return map[string]*bool{
+		"is_anonymous":           &r.IsAnonymous,
+		"can_manage_chat":        &r.CanManageChat,
+		"can_delete_messages":    &r.CanDeleteMessages,
+		"can_manage_video_chats": &r.CanManageVideoChats,
+		"can_restrict_members":   &r.CanRestrictMembers,
+		"can_promote_members":    &r.CanPromoteMembers,
+		"can_change_info":        &r.CanChangeInfo,
+		"can_invite_users":       &r.CanInviteUsers,
+		"can_post_stories":       &r.CanPostStories,
+		"can_edit_stories":       &r.CanEditStories,
+		"can_delete_stories":     &r.CanDeleteStories,
+		"can_post_messages":      &r.CanPostMessages,
+		"can_edit_messages":      &r.CanEditMessages,
+		"can_pin_messages":       &r.CanPinMessages,
+		"can_manage_topics":      &r.CanManageTopics,
+	}
+}
+
+// Enabled возвращает имена включенных прав в алфавитном порядке
+func (r ChatAdministratorRights) Enabled() []string {
+	var names []string
+	for name, v := range r.fields() {
+		if *v {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsZero сообщает, что ни одно право не включено
+func (r ChatAdministratorRights) IsZero() bool {
+	return r == ChatAdministratorRights{}
+}
+
+// String возвращает включенные права через запятую или "нет"
+func (r ChatAdministratorRights) String() string {
+	if r.IsZero() {
+		return "нет"
+	}
+	return strings.Join(r.Enabled(), ",")
+}
+
+// ParseAdministratorRights собирает права из имен Bot API: "can_delete_messages", "can_pin_messages"...
+func ParseAdministratorRights(names []string) (ChatAdministratorRights, error) {
+	var r ChatAdministratorRights
+	fields := r.fields()
+	for _, name := range names {
+		v, ok := fields[strings.TrimSpace(name)]
+		if !ok {
+			return r, fmt.Errorf("неизвестное право администратора %q", name)
+		}
+		*v = true
+	}
+	return r, nil
+}
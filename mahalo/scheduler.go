@@ -0,0 +1,195 @@
+package mahalo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Причины ожидания в WaitEvent
+const (
+	WaitRateLimit = "rate_limit" // соблюдаем собственный темп отправки
+	WaitFlood     = "flood_wait" // MTProto вернул FLOOD_WAIT_X
+	WaitBotFather = "botfather"  // BotFather написал «try again in N seconds»
+	WaitNewBot    = "new_bot"    // даем BotFather время обработать только что созданного бота
+)
+
+// DefaultMessageInterval — интервал между сообщениями BotFather по умолчанию
+const DefaultMessageInterval = time.Second
+
+// DefaultMaxWait — самое долгое ожидание по умолчанию; дольше — WaitTooLongError
+const DefaultMaxWait = 10 * time.Minute
+
+// WaitTooLongError — Telegram или BotFather просят ждать дольше, чем разрешено MaxWait.
+// Операцию стоит повторить не раньше Until.
+type WaitTooLongError struct {
+	Wait   time.Duration
+	Max    time.Duration
+	Until  time.Time
+	Reason string // WaitFlood или WaitBotFather
+}
+
+func (e *WaitTooLongError) Error() string {
+	return fmt.Sprintf("%s: нужно ждать %s (до %s), это больше допустимых %s",
+		e.Reason, e.Wait, e.Until.Format(time.RFC3339), e.Max)
+}
+
+// WaitEvent — сведения об ожидании перед следующим сообщением BotFather
+type WaitEvent struct {
+	Wait   time.Duration
+	Until  time.Time
+	Reason string // WaitRateLimit, WaitFlood, WaitBotFather или WaitNewBot
+}
+
+// Scheduler распределяет исходящие сообщения BotFather одного аккаунта во времени:
+// корзина токенов задает темп (не чаще одного сообщения в Interval, до Burst подряд),
+// а FLOOD_WAIT и просьбы BotFather подождать приостанавливают отправку целиком.
+// Безопасен для одновременного использования.
+type Scheduler struct {
+	// OnWait вызывается перед каждым ожиданием; задается до начала работы
+	OnWait func(WaitEvent)
+	// MaxWait — самая долгая пауза, которую Block согласен ждать; 0 — без ограничения
+	MaxWait time.Duration
+
+	mu           sync.Mutex
+	interval     time.Duration
+	burst        int
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	blockReason  string
+	now          func() time.Time // часы; nil — time.Now, в тестах подменяются
+}
+
+// NewScheduler создает планировщик: не чаще одного сообщения в interval, до burst подряд.
+// interval <= 0 отключает ограничение темпа, но паузы по FLOOD_WAIT соблюдаются.
+func NewScheduler(interval time.Duration, burst int) *Scheduler {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Scheduler{MaxWait: DefaultMaxWait, interval: interval, burst: burst, tokens: float64(burst)}
+}
+
+// Wait ждет, пока можно отправить следующее сообщение, и занимает для него токен
+func (s *Scheduler) Wait(ctx context.Context) error {
+	for {
+		event, ok := s.reserve(s.clock())
+		if ok {
+			return nil
+		}
+		if err := s.sleep(ctx, event); err != nil {
+			return err
+		}
+	}
+}
+
+// Pause ждет окончания паузы после FLOOD_WAIT или просьбы BotFather, не занимая токен
+func (s *Scheduler) Pause(ctx context.Context) error {
+	for {
+		wait := s.CurrentWait()
+		if wait <= 0 {
+			return nil
+		}
+		s.mu.Lock()
+		event := WaitEvent{Wait: wait, Until: s.blockedUntil, Reason: s.blockReason}
+		s.mu.Unlock()
+		if err := s.sleep(ctx, event); err != nil {
+			return err
+		}
+	}
+}
+
+// Block приостанавливает отправку на d по причине reason. Более ранняя пауза не сокращается.
+// Если d больше MaxWait, пауза не ставится и возвращается *WaitTooLongError.
+func (s *Scheduler) Block(d time.Duration, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until := s.clock().Add(d)
+	if s.MaxWait > 0 && d > s.MaxWait {
+		return &WaitTooLongError{Wait: d, Max: s.MaxWait, Until: until, Reason: reason}
+	}
+	if until.After(s.blockedUntil) {
+		s.blockedUntil, s.blockReason = until, reason
+	}
+	return nil
+}
+
+// CurrentWait возвращает, сколько еще продлится пауза после FLOOD_WAIT или просьбы BotFather
+func (s *Scheduler) CurrentWait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(s.blockedUntil.Sub(s.clock()), 0)
+}
+
+func (s *Scheduler) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// reserve занимает токен, если это можно сделать сейчас, иначе говорит, сколько ждать
+func (s *Scheduler) reserve(now time.Time) (WaitEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Before(s.blockedUntil) {
+		return WaitEvent{Wait: s.blockedUntil.Sub(now), Until: s.blockedUntil, Reason: s.blockReason}, false
+	}
+	if s.interval <= 0 {
+		return WaitEvent{}, true
+	}
+
+	if !s.last.IsZero() {
+		s.tokens = min(s.tokens+float64(now.Sub(s.last))/float64(s.interval), float64(s.burst))
+	}
+	s.last = now
+	if s.tokens >= 1 {
+		s.tokens--
+		return WaitEvent{}, true
+	}
+
+	wait := time.Duration((1 - s.tokens) * float64(s.interval))
+	return WaitEvent{Wait: wait, Until: now.Add(wait), Reason: WaitRateLimit}, false
+}
+
+func (s *Scheduler) sleep(ctx context.Context, event WaitEvent) error {
+	logger := Logger(ctx)
+	switch event.Reason {
+	case WaitRateLimit, WaitNewBot:
+		logger.Debug("ждем перед отправкой BotFather", "wait", event.Wait, "reason", event.Reason)
+	default:
+		logger.Warn("Telegram просит подождать", "wait", event.Wait, "reason", event.Reason)
+	}
+	if s.OnWait != nil {
+		s.OnWait(event)
+	}
+
+	timer := time.NewTimer(event.Wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type schedulerKey struct{}
+
+// defaultScheduler используется, когда планировщик не передан через WithScheduler
+var defaultScheduler = NewScheduler(DefaultMessageInterval, 1)
+
+// WithScheduler возвращает контекст, в котором сообщения BotFather отправляются через s
+func WithScheduler(ctx context.Context, s *Scheduler) context.Context {
+	return context.WithValue(ctx, schedulerKey{}, s)
+}
+
+// SchedulerFromContext возвращает планировщик из контекста или общий планировщик по умолчанию
+func SchedulerFromContext(ctx context.Context) *Scheduler {
+	if s, ok := ctx.Value(schedulerKey{}).(*Scheduler); ok && s != nil {
+		return s
+	}
+	return defaultScheduler
+}
@@ -0,0 +1,191 @@
+package mahalo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock — часы планировщика, которые двигает тест
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestScheduler(interval time.Duration, burst int) (*Scheduler, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler(interval, burst)
+	s.now = clock.now
+	return s, clock
+}
+
+func TestSchedulerReserve(t *testing.T) {
+	type step struct {
+		at       time.Duration // смещение от начала
+		wantOK   bool
+		wantWait time.Duration
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		burst    int
+		steps    []step
+	}{
+		{
+			name:     "одно сообщение в секунду",
+			interval: time.Second, burst: 1,
+			steps: []step{
+				{0, true, 0},
+				{0, false, time.Second},
+				{400 * time.Millisecond, false, 600 * time.Millisecond},
+				{time.Second, true, 0},
+				{time.Second, false, time.Second},
+			},
+		},
+		{
+			name:     "burst копится, но не больше предела",
+			interval: time.Second, burst: 3,
+			steps: []step{
+				{0, true, 0},
+				{0, true, 0},
+				{0, true, 0},
+				{0, false, time.Second},
+				{time.Hour, true, 0},
+				{time.Hour, true, 0},
+				{time.Hour, true, 0},
+				{time.Hour, false, time.Second},
+			},
+		},
+		{
+			name:     "без ограничения темпа",
+			interval: 0, burst: 1,
+			steps: []step{
+				{0, true, 0},
+				{0, true, 0},
+				{0, true, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestScheduler(tt.interval, tt.burst)
+			start := clock.t
+			for i, st := range tt.steps {
+				event, ok := s.reserve(start.Add(st.at))
+				if ok != st.wantOK || event.Wait != st.wantWait {
+					t.Fatalf("шаг %d (+%s): ok=%v wait=%s, ожидалось ok=%v wait=%s",
+						i, st.at, ok, event.Wait, st.wantOK, st.wantWait)
+				}
+				if !ok && event.Reason != WaitRateLimit {
+					t.Errorf("шаг %d: причина %q, ожидалась %q", i, event.Reason, WaitRateLimit)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerBlock(t *testing.T) {
+	type block struct {
+		d      time.Duration
+		reason string
+	}
+	tests := []struct {
+		name       string
+		blocks     []block
+		wantWait   time.Duration
+		wantReason string
+	}{
+		{"одна пауза", []block{{10 * time.Second, WaitFlood}}, 10 * time.Second, WaitFlood},
+		{"короткая не сокращает длинную", []block{{10 * time.Second, WaitFlood}, {3 * time.Second, WaitBotFather}}, 10 * time.Second, WaitFlood},
+		{"длинная продлевает короткую", []block{{3 * time.Second, WaitNewBot}, {10 * time.Second, WaitBotFather}}, 10 * time.Second, WaitBotFather},
+		{"длиннее MaxWait не ставится", []block{{5 * time.Second, WaitNewBot}, {time.Hour, WaitFlood}}, 5 * time.Second, WaitNewBot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestScheduler(time.Second, 1)
+			s.MaxWait = 10 * time.Minute
+			for _, b := range tt.blocks {
+				err := s.Block(b.d, b.reason)
+				var tooLong *WaitTooLongError
+				switch {
+				case b.d > s.MaxWait && !errors.As(err, &tooLong):
+					t.Fatalf("Block(%s) = %v, ожидалась *WaitTooLongError", b.d, err)
+				case b.d <= s.MaxWait && err != nil:
+					t.Fatalf("Block(%s) = %v", b.d, err)
+				}
+			}
+
+			if got := s.CurrentWait(); got != tt.wantWait {
+				t.Errorf("CurrentWait() = %s, ожидалось %s", got, tt.wantWait)
+			}
+			// Пока идет пауза, токен не выдается, а причина — от самой длинной паузы
+			event, ok := s.reserve(clock.t)
+			if ok || event.Wait != tt.wantWait || event.Reason != tt.wantReason {
+				t.Errorf("reserve: ok=%v wait=%s reason=%q, ожидалось ожидание %s по %q",
+					ok, event.Wait, event.Reason, tt.wantWait, tt.wantReason)
+			}
+
+			clock.t = clock.t.Add(tt.wantWait)
+			if got := s.CurrentWait(); got != 0 {
+				t.Errorf("после паузы CurrentWait() = %s, ожидался 0", got)
+			}
+			if _, ok := s.reserve(clock.t); !ok {
+				t.Error("после паузы токен должен выдаваться")
+			}
+		})
+	}
+}
+
+func TestSchedulerPause(t *testing.T) {
+	tests := []struct {
+		name       string
+		block      time.Duration
+		cancel     bool
+		wantErr    error
+		wantEvents int
+	}{
+		{"без паузы возвращается сразу", 0, false, nil, 0},
+		{"ждет окончания паузы", 20 * time.Millisecond, false, nil, 1},
+		{"отмена контекста прерывает ожидание", time.Hour, true, context.Canceled, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Pause спит по-настоящему, поэтому здесь часы настоящие
+			s := NewScheduler(time.Second, 1)
+			s.MaxWait = 0
+			var events []WaitEvent
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s.OnWait = func(e WaitEvent) {
+				events = append(events, e)
+				if tt.cancel {
+					cancel()
+				}
+			}
+			if tt.block > 0 {
+				if err := s.Block(tt.block, WaitBotFather); err != nil {
+					t.Fatalf("Block: %v", err)
+				}
+			}
+
+			// Pause не занимает токен: после нее сообщение можно отправить сразу
+			if err := s.Pause(ctx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Pause() = %v, ожидалось %v", err, tt.wantErr)
+			}
+			if len(events) != tt.wantEvents {
+				t.Fatalf("ожиданий %d, ожидалось %d", len(events), tt.wantEvents)
+			}
+			if len(events) > 0 && events[0].Reason != WaitBotFather {
+				t.Errorf("причина %q, ожидалась %q", events[0].Reason, WaitBotFather)
+			}
+			if tt.wantErr == nil {
+				if _, ok := s.reserve(time.Now()); !ok {
+					t.Error("после Pause токен должен быть свободен")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package mahalo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Типы областей видимости команд Bot API (BotCommandScope*)
+const (
+	ScopeDefault               = "default"
+	ScopeAllPrivateChats       = "all_private_chats"
+	ScopeAllGroupChats         = "all_group_chats"
+	ScopeAllChatAdministrators = "all_chat_administrators"
+	ScopeChat                  = "chat"
+	ScopeChatAdministrators    = "chat_administrators"
+	ScopeChatMember            = "chat_member"
+)
+
+// BotCommandScope — область видимости списка команд. Для chat, chat_administrators
+// и chat_member нужен ChatID (числовой id или @username группы), для chat_member — еще UserID.
+type BotCommandScope struct {
+	Type   string `json:"type"`
+	ChatID string `json:"chat_id,omitempty"`
+	UserID int64  `json:"user_id,omitempty"`
+}
+
+// DefaultScope — команды для всех чатов, для которых не задана более узкая область
+func DefaultScope() BotCommandScope {
+	return BotCommandScope{Type: ScopeDefault}
+}
+
+// AllPrivateChatsScope — команды для всех личных чатов
+func AllPrivateChatsScope() BotCommandScope {
+	return BotCommandScope{Type: ScopeAllPrivateChats}
+}
+
+// AllGroupChatsScope — команды для всех групп и супергрупп
+func AllGroupChatsScope() BotCommandScope {
+	return BotCommandScope{Type: ScopeAllGroupChats}
+}
+
+// AllChatAdministratorsScope — команды для администраторов всех групп
+func AllChatAdministratorsScope() BotCommandScope {
+	return BotCommandScope{Type: ScopeAllChatAdministrators}
+}
+
+// ChatScope — команды для одного чата
+func ChatScope(chatID string) BotCommandScope {
+	return BotCommandScope{Type: ScopeChat, ChatID: chatID}
+}
+
+// ChatAdministratorsScope — команды для администраторов одной группы
+func ChatAdministratorsScope(chatID string) BotCommandScope {
+	return BotCommandScope{Type: ScopeChatAdministrators, ChatID: chatID}
+}
+
+// ChatMemberScope — команды для одного участника группы
+func ChatMemberScope(chatID string, userID int64) BotCommandScope {
+	return BotCommandScope{Type: ScopeChatMember, ChatID: chatID, UserID: userID}
+}
+
+// IsDefault сообщает, что это область по умолчанию (в том числе пустая)
+func (s BotCommandScope) IsDefault() bool {
+	return s.Type == "" || s.Type == ScopeDefault
+}
+
+// Validate проверяет тип области и наличие нужных для него полей
+func (s BotCommandScope) Validate() error {
+	switch s.Type {
+	case "", ScopeDefault, ScopeAllPrivateChats, ScopeAllGroupChats, ScopeAllChatAdministrators:
+		if s.ChatID != "" || s.UserID != 0 {
+			return fmt.Errorf("область %q не принимает chat_id и user_id", s.Type)
+		}
+	case ScopeChat, ScopeChatAdministrators:
+		if s.ChatID == "" {
+			return fmt.Errorf("для области %q нужен chat_id", s.Type)
+		}
+		if s.UserID != 0 {
+			return fmt.Errorf("область %q не принимает user_id", s.Type)
+		}
+	case ScopeChatMember:
+		if s.ChatID == "" || s.UserID == 0 {
+			return fmt.Errorf("для области %q нужны chat_id и user_id", s.Type)
+		}
+	default:
+		return fmt.Errorf("неизвестная область видимости команд %q", s.Type)
+	}
+	return nil
+}
+
+// String возвращает короткую запись области: "all_group_chats", "chat:-100123", "chat_member:-100123:42"
+func (s BotCommandScope) String() string {
+	out := s.Type
+	if s.IsDefault() {
+		out = ScopeDefault
+	}
+	if s.ChatID != "" {
+		out += ":" + s.ChatID
+	}
+	if s.UserID != 0 {
+		out += ":" + strconv.FormatInt(s.UserID, 10)
+	}
+	return out
+}
+
+// ParseBotCommandScope разбирает запись области в формате String
+func ParseBotCommandScope(text string) (BotCommandScope, error) {
+	parts := strings.Split(strings.TrimSpace(text), ":")
+	s := BotCommandScope{Type: parts[0]}
+	switch len(parts) {
+	case 1:
+	case 2:
+		s.ChatID = parts[1]
+	case 3:
+		userID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return s, fmt.Errorf("область %q: некорректный user_id %q", text, parts[2])
+		}
+		s.ChatID, s.UserID = parts[1], userID
+	default:
+		return s, fmt.Errorf("область %q: ожидается тип[:chat_id[:user_id]]", text)
+	}
+	return s, s.Validate()
+}
@@ -2,7 +2,10 @@ package mahalo
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Ошибки BotFather
@@ -78,14 +81,16 @@ func IsPrompt(message string, keywords []string) bool {
 
 // FormatCommands форматирует команды для BotFather
 func FormatCommands(commands map[string]string) string {
+	return FormatCommandList(CommandsFromMap(commands))
+}
+
+// FormatCommandList форматирует упорядоченный список команд для BotFather
+func FormatCommandList(commands []BotCommand) string {
 	var builder strings.Builder
-	for command, description := range commands {
+	for _, c := range commands {
 		// BotFather expects commands without a leading slash when receiving the list
-		cmd := strings.TrimSpace(command)
-		if strings.HasPrefix(cmd, "/") {
-			cmd = strings.TrimPrefix(cmd, "/")
-		}
-		builder.WriteString(cmd + " - " + description + "\n")
+		cmd := strings.TrimPrefix(strings.TrimSpace(c.Command), "/")
+		builder.WriteString(cmd + " - " + strings.TrimSpace(c.Description) + "\n")
 	}
 	return strings.TrimSpace(builder.String())
 }
@@ -102,8 +107,7 @@ func CheckBotFatherError(message string) error {
 	if strings.Contains(msgLower, "too many attempts") ||
 		strings.Contains(msgLower, "please try again in") {
 		// Извлекаем время ожидания
-		seconds := ExtractWaitTime(message)
-		return fmt.Errorf("%s: %d seconds", ErrTooManyAttempts, seconds)
+		return fmt.Errorf("%s: %s", ErrTooManyAttempts, ExtractWaitTime(message))
 	}
 
 	if strings.Contains(msgLower, "invalid username") ||
@@ -118,26 +122,33 @@ func CheckBotFatherError(message string) error {
 	return nil
 }
 
-// extractWaitTime извлекает время ожидания из сообщения об ошибке
-func ExtractWaitTime(message string) int {
-	// Ищем числа в сообщении
-	words := strings.Fields(message)
-	for _, word := range words {
-		if strings.Contains(word, "seconds") {
-			// Пробуем извлечь число перед "seconds"
-			for i := 0; i < len(word); i++ {
-				if word[i] >= '0' && word[i] <= '9' {
-					start := i
-					for i < len(word) && word[i] >= '0' && word[i] <= '9' {
-						i++
-					}
-					secondsStr := word[start:i]
-					var seconds int
-					fmt.Sscanf(secondsStr, "%d", &seconds)
-					return seconds
-				}
-			}
+// waitTimePattern находит «число единица»: "30 seconds", "2 minutes", "8 hours", "1 day", "45s".
+// Конец слова проверяется в ExtractWaitTime: \b не сработал бы в "1h30m".
+var waitTimePattern = regexp.MustCompile(`(?i)(\d+)\s*(seconds|second|secs|sec|s|minutes|minute|mins|min|m|hours|hour|hrs|hr|h|days|day|d)`)
+
+// DefaultWaitTime — ожидание, если BotFather не назвал время
+const DefaultWaitTime = 60 * time.Second
+
+// ExtractWaitTime извлекает время ожидания из сообщения об ошибке: секунды, минуты,
+// часы и дни, с пробелом между числом и единицей или без. Несколько частей
+// ("1 hour 30 minutes") складываются. Если время не найдено — DefaultWaitTime.
+func ExtractWaitTime(message string) time.Duration {
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+
+	var total time.Duration
+	for _, loc := range waitTimePattern.FindAllStringSubmatchIndex(message, -1) {
+		// За единицей не должна идти буква: "5 more" — не минуты
+		if end := loc[1]; end < len(message) && isASCIILetter(rune(message[end])) {
+			continue
 		}
+		n, err := strconv.Atoi(message[loc[2]:loc[3]])
+		if err != nil {
+			continue
+		}
+		total += time.Duration(n) * units[strings.ToLower(message[loc[4]:loc[5]])[0]]
+	}
+	if total <= 0 {
+		return DefaultWaitTime
 	}
-	return 60 // дефолтное значение
+	return total
 }
@@ -0,0 +1,38 @@
+package mahalo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractWaitTime(t *testing.T) {
+	tests := []struct {
+		message string
+		want    time.Duration
+	}{
+		{"Sorry, too many attempts. Please try again in 30 seconds.", 30 * time.Second},
+		{"Please try again in 1 second", time.Second},
+		{"Too many attempts. Please try again in 5 minutes.", 5 * time.Minute},
+		{"Please try again in 1 minute", time.Minute},
+		{"Please try again in 8 hours.", 8 * time.Hour},
+		{"Please try again in 1 hour", time.Hour},
+		{"Please try again in 2 days", 48 * time.Hour},
+		{"Please try again in 1 day.", 24 * time.Hour},
+		{"Please try again in 45s", 45 * time.Second},
+		{"Please try again in 10m", 10 * time.Minute},
+		{"Please try again in 3h", 3 * time.Hour},
+		{"Please try again in 1d", 24 * time.Hour},
+		{"Please try again in 1 hour 30 minutes", 90 * time.Minute},
+		{"Please try again in 1h30m", 90 * time.Minute},
+		{"Please try again in 1 day, 2 hours and 5 seconds", 26*time.Hour + 5*time.Second},
+		{"PLEASE TRY AGAIN IN 2 MINUTES", 2 * time.Minute},
+		{"Please try again in 5 more attempts", DefaultWaitTime},
+		{"Please try again later", DefaultWaitTime},
+		{"", DefaultWaitTime},
+	}
+	for _, tt := range tests {
+		if got := ExtractWaitTime(tt.message); got != tt.want {
+			t.Errorf("ExtractWaitTime(%q) = %s, ожидалось %s", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package mahalo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ограничения Telegram для команд бота
+const (
+	MaxCommands              = 100
+	MaxCommandLength         = 32
+	MinCommandDescriptionLen = 1
+	MaxCommandDescriptionLen = 256
+)
+
+// CommandViolation описывает одно нарушение правил Telegram в списке команд
+type CommandViolation struct {
+	Command string // команда в том виде, в каком её передали
+	Field   string // "command", "description" или "list"
+	Reason  string
+}
+
+func (v CommandViolation) String() string {
+	if v.Command == "" {
+		return fmt.Sprintf("%s: %s", v.Field, v.Reason)
+	}
+	return fmt.Sprintf("%q (%s): %s", v.Command, v.Field, v.Reason)
+}
+
+// CommandsError содержит все нарушения, найденные ValidateCommands
+type CommandsError struct {
+	Violations []CommandViolation
+}
+
+func (e *CommandsError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("некорректный список команд (%d нарушений): %s", len(e.Violations), strings.Join(parts, "; "))
+}
+
+// BotCommand — команда бота с описанием, в порядке показа в меню
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// CommandsFromMap превращает map команд в список, отсортированный по имени команды
+func CommandsFromMap(commands map[string]string) []BotCommand {
+	list := make([]BotCommand, 0, len(commands))
+	for command, description := range commands {
+		list = append(list, BotCommand{Command: command, Description: description})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Command < list[j].Command })
+	return list
+}
+
+// ValidateCommands проверяет команды по правилам Telegram и возвращает
+// *CommandsError со всеми найденными нарушениями сразу, либо nil.
+// Лидирующий '/' допускается, как и в FormatCommands.
+func ValidateCommands(commands map[string]string) error {
+	return ValidateCommandList(CommandsFromMap(commands))
+}
+
+// ValidateCommandList — то же, что ValidateCommands, но для упорядоченного списка
+func ValidateCommandList(commands []BotCommand) error {
+	var violations []CommandViolation
+
+	if len(commands) == 0 {
+		violations = append(violations, CommandViolation{Field: "list", Reason: "список команд пуст"})
+	}
+	if len(commands) > MaxCommands {
+		violations = append(violations, CommandViolation{
+			Field:  "list",
+			Reason: fmt.Sprintf("слишком много команд: %d (максимум %d)", len(commands), MaxCommands),
+		})
+	}
+
+	seen := make(map[string]string, len(commands))
+	for _, c := range commands {
+		name := strings.TrimPrefix(strings.TrimSpace(c.Command), "/")
+
+		if reason := commandNameViolation(name); reason != "" {
+			violations = append(violations, CommandViolation{Command: c.Command, Field: "command", Reason: reason})
+		} else if prev, ok := seen[name]; ok {
+			violations = append(violations, CommandViolation{
+				Command: c.Command,
+				Field:   "command",
+				Reason:  fmt.Sprintf("дублирует команду %q", prev),
+			})
+		} else {
+			seen[name] = c.Command
+		}
+
+		description := strings.TrimSpace(c.Description)
+		if n := utf8.RuneCountInString(description); n < MinCommandDescriptionLen || n > MaxCommandDescriptionLen {
+			violations = append(violations, CommandViolation{
+				Command: c.Command,
+				Field:   "description",
+				Reason: fmt.Sprintf("длина описания %d символов, допустимо от %d до %d",
+					n, MinCommandDescriptionLen, MaxCommandDescriptionLen),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &CommandsError{Violations: violations}
+	}
+	return nil
+}
+
+// commandNameViolation возвращает причину, по которой имя команды недопустимо, или пустую строку
+func commandNameViolation(name string) string {
+	if name == "" {
+		return "пустое имя команды"
+	}
+	if len(name) > MaxCommandLength {
+		return fmt.Sprintf("имя длиннее %d символов", MaxCommandLength)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return fmt.Sprintf("недопустимый символ %q (разрешены строчные латинские буквы, цифры и '_')", r)
+		}
+	}
+	return ""
+}
+
+// Ограничения Telegram для username бота
+const (
+	MinBotUsernameLength = 5
+	MaxBotUsernameLength = 32
+	BotUsernameSuffix    = "bot"
+)
+
+// UsernameError описывает, почему username бота не подходит
+type UsernameError struct {
+	Username string
+	Reason   string
+}
+
+func (e *UsernameError) Error() string {
+	return fmt.Sprintf("некорректный username %q: %s", e.Username, e.Reason)
+}
+
+// NormalizeBotUsername приводит username к виду, который ожидает BotFather:
+// убирает пробелы, ссылки вида https://t.me/ и ведущий '@'.
+func NormalizeBotUsername(username string) string {
+	u := strings.TrimSpace(username)
+	for _, prefix := range []string{"https://", "http://"} {
+		if len(u) >= len(prefix) && strings.EqualFold(u[:len(prefix)], prefix) {
+			u = u[len(prefix):]
+			break
+		}
+	}
+	for _, prefix := range []string{"t.me/", "telegram.me/"} {
+		if len(u) >= len(prefix) && strings.EqualFold(u[:len(prefix)], prefix) {
+			u = u[len(prefix):]
+			break
+		}
+	}
+	u = strings.TrimPrefix(u, "@")
+	return strings.TrimRight(u, "/")
+}
+
+// ValidateBotUsername проверяет username по правилам Telegram: 5–32 символа,
+// латинские буквы, цифры и '_', начинается с буквы и заканчивается на "bot"
+// (без учёта регистра). Возвращает *UsernameError или nil.
+// Username должен быть уже нормализован через NormalizeBotUsername.
+func ValidateBotUsername(username string) error {
+	if len(username) < MinBotUsernameLength || len(username) > MaxBotUsernameLength {
+		return &UsernameError{Username: username, Reason: fmt.Sprintf("длина %d, допустимо от %d до %d символов",
+			len(username), MinBotUsernameLength, MaxBotUsernameLength)}
+	}
+	if !isASCIILetter(rune(username[0])) {
+		return &UsernameError{Username: username, Reason: "должен начинаться с латинской буквы"}
+	}
+	for _, r := range username {
+		if !isASCIILetter(r) && (r < '0' || r > '9') && r != '_' {
+			return &UsernameError{Username: username, Reason: fmt.Sprintf("недопустимый символ %q", r)}
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(username), BotUsernameSuffix) {
+		return &UsernameError{Username: username, Reason: "должен заканчиваться на 'bot'"}
+	}
+	return nil
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
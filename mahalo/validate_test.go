@@ -0,0 +1,113 @@
+package mahalo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommandList(t *testing.T) {
+	many := make([]BotCommand, MaxCommands+1)
+	for i := range many {
+		many[i] = BotCommand{Command: fmt.Sprintf("cmd%d", i), Description: "Описание"}
+	}
+
+	type violation struct{ command, field string }
+	tests := []struct {
+		name     string
+		commands []BotCommand
+		want     []violation // nil — ошибок нет
+	}{
+		{"корректный список", []BotCommand{{"start", "Начать"}, {"/help", "Помощь"}, {"set_lang2", "Язык"}}, nil},
+		{"пустой список", nil, []violation{{"", "list"}}},
+		{"слишком много команд", many, []violation{{"", "list"}}},
+		{"пустое имя", []BotCommand{{"/", "Описание"}}, []violation{{"/", "command"}}},
+		{"заглавные буквы", []BotCommand{{"Start", "Начать"}}, []violation{{"Start", "command"}}},
+		{"дефис", []BotCommand{{"set-lang", "Язык"}}, []violation{{"set-lang", "command"}}},
+		{"кириллица", []BotCommand{{"старт", "Начать"}}, []violation{{"старт", "command"}}},
+		{"ровно 32 символа", []BotCommand{{strings.Repeat("a", MaxCommandLength), "Описание"}}, nil},
+		{"длиннее 32 символов", []BotCommand{{strings.Repeat("a", MaxCommandLength+1), "Описание"}}, []violation{{strings.Repeat("a", MaxCommandLength+1), "command"}}},
+		{"дубликат с '/'", []BotCommand{{"start", "Начать"}, {"/start", "Еще раз"}}, []violation{{"/start", "command"}}},
+		{"пустое описание", []BotCommand{{"start", "   "}}, []violation{{"start", "description"}}},
+		{"длинное описание в символах, а не байтах", []BotCommand{{"start", strings.Repeat("я", MaxCommandDescriptionLen)}}, nil},
+		{"описание длиннее 256 символов", []BotCommand{{"start", strings.Repeat("я", MaxCommandDescriptionLen+1)}}, []violation{{"start", "description"}}},
+		{"все нарушения сразу", []BotCommand{{"Bad", ""}, {"ok", "Хорошо"}, {"/ok", ""}}, []violation{
+			{"Bad", "command"}, {"Bad", "description"}, {"/ok", "command"}, {"/ok", "description"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCommandList(tt.commands)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateCommandList: %v", err)
+				}
+				return
+			}
+			var cmdErr *CommandsError
+			if !errors.As(err, &cmdErr) {
+				t.Fatalf("ValidateCommandList = %v, ожидалась *CommandsError", err)
+			}
+			var got []violation
+			for _, v := range cmdErr.Violations {
+				got = append(got, violation{v.Command, v.Field})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("нарушения %v, ожидалось %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeBotUsername(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"mybot", "mybot"},
+		{"  @MyBot  ", "MyBot"},
+		{"t.me/my_bot", "my_bot"},
+		{"https://t.me/my_bot/", "my_bot"},
+		{"HTTP://T.ME/My_Bot", "My_Bot"},
+		{"https://telegram.me/@my_bot", "my_bot"},
+		{"@", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeBotUsername(tt.in); got != tt.want {
+			t.Errorf("NormalizeBotUsername(%q) = %q, ожидалось %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBotUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		wantErr  bool
+	}{
+		{"my_bot", false},
+		{"MyBot", false},
+		{"shop_BOT", false},
+		{"a1bot", false},
+		{"abot", true}, // короче 5 символов
+		{"a" + strings.Repeat("x", 28) + "bot", false}, // ровно 32
+		{"a" + strings.Repeat("x", 29) + "bot", true},  // 33 символа
+		{"1shopbot", true},
+		{"_shopbot", true},
+		{"shop-bot", true},
+		{"магазинbot", true},
+		{"shop_botik", true},
+		{"@shopbot", true}, // не нормализован
+	}
+	for _, tt := range tests {
+		err := ValidateBotUsername(tt.username)
+		var usernameErr *UsernameError
+		switch {
+		case tt.wantErr && !errors.As(err, &usernameErr):
+			t.Errorf("ValidateBotUsername(%q) = %v, ожидалась *UsernameError", tt.username, err)
+		case !tt.wantErr && err != nil:
+			t.Errorf("ValidateBotUsername(%q) = %v", tt.username, err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package ohana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/ghodss/yaml"
+)
+
+// Ограничения Telegram для текстов профиля бота
+const (
+	MaxBotNameLength        = 64
+	MaxBotDescriptionLength = 512
+	MaxBotAboutLength       = 120
+)
+
+// Manifest описывает полный профиль бота. Формат — YAML или JSON с одинаковыми ключами.
+// Должен быть указан username (существующий или желаемый) либо base_username для автоподбора.
+type Manifest struct {
+	Name         string              `json:"name"`
+	Username     string              `json:"username,omitempty"`
+	BaseUsername string              `json:"base_username,omitempty"`
+	Description  string              `json:"description,omitempty"`
+	About        string              `json:"about,omitempty"`
+	Commands     []mahalo.BotCommand `json:"commands,omitempty"`
+	Userpic      string              `json:"userpic,omitempty"`
+	Settings     *BotSettings        `json:"settings,omitempty"`
+	Domain       string              `json:"domain,omitempty"`
+	MenuButton   *MenuButton         `json:"menu_button,omitempty"`
+	// Localized — тексты для отдельных языков: код ISO 639-1 → имя, описание, about, команды
+	Localized map[string]LocalizedProfile `json:"localized,omitempty"`
+	// ScopedCommands — отдельные меню команд для личных чатов, групп, администраторов и т.п.
+	ScopedCommands []ScopedCommands `json:"scoped_commands,omitempty"`
+	Webhook        *Webhook         `json:"webhook,omitempty"`
+	AdminRights    *AdminRights     `json:"admin_rights,omitempty"`
+}
+
+// BotSettings — переключатели поведения бота; nil/пустое значение означает «не трогать»
+type BotSettings struct {
+	Privacy           *bool  `json:"privacy,omitempty"`
+	JoinGroups        *bool  `json:"join_groups,omitempty"`
+	InlinePlaceholder string `json:"inline_placeholder,omitempty"`
+}
+
+// MenuButton — кнопка меню, открывающая веб-приложение
+type MenuButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// ManifestError содержит все проблемы, найденные при проверке манифеста
+type ManifestError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ManifestError) Error() string {
+	where := "манифест"
+	if e.Path != "" {
+		where = "манифест " + e.Path
+	}
+	return fmt.Sprintf("%s некорректен: %s", where, strings.Join(e.Problems, "; "))
+}
+
+// LoadManifest читает и проверяет манифест из файла .yaml, .yml или .json.
+// Относительный путь к userpic считается от каталога манифеста.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать манифест: %w", err)
+	}
+
+	m, err := decodeManifest(data)
+	if err != nil {
+		return nil, &ManifestError{Path: path, Problems: []string{err.Error()}}
+	}
+
+	if m.Userpic != "" && !filepath.IsAbs(m.Userpic) {
+		m.Userpic = filepath.Join(filepath.Dir(path), m.Userpic)
+	}
+
+	if err := m.Validate(); err != nil {
+		if me, ok := err.(*ManifestError); ok {
+			me.Path = path
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseManifest разбирает и проверяет манифест из YAML или JSON
+func ParseManifest(data []byte) (*Manifest, error) {
+	m, err := decodeManifest(data)
+	if err != nil {
+		return nil, &ManifestError{Problems: []string{err.Error()}}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeManifest разбирает YAML (JSON — его частный случай), запрещая неизвестные ключи
+func decodeManifest(data []byte) (*Manifest, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("ошибка разбора: %w", err)
+	}
+	return &m, nil
+}
+
+// Validate проверяет манифест по схеме и правилам Telegram и возвращает
+// *ManifestError со всеми найденными проблемами сразу
+func (m *Manifest) Validate() error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if n := utf8.RuneCountInString(strings.TrimSpace(m.Name)); n == 0 || n > MaxBotNameLength {
+		addf("name: длина %d, допустимо от 1 до %d символов", n, MaxBotNameLength)
+	}
+
+	switch {
+	case m.Username == "" && m.BaseUsername == "":
+		addf("нужно указать username или base_username")
+	case m.Username != "" && m.BaseUsername != "":
+		addf("username и base_username взаимоисключающие")
+	case m.Username != "":
+		if err := mahalo.ValidateBotUsername(mahalo.NormalizeBotUsername(m.Username)); err != nil {
+			addf("username: %v", err)
+		}
+	default:
+		candidate := mahalo.NumericSuffix{}.Candidate(mahalo.NormalizeBotUsername(m.BaseUsername), 1).String()
+		if err := mahalo.ValidateBotUsername(candidate); err != nil {
+			addf("base_username: %v", err)
+		}
+	}
+
+	if n := utf8.RuneCountInString(m.Description); n > MaxBotDescriptionLength {
+		addf("description: длина %d, максимум %d символов", n, MaxBotDescriptionLength)
+	}
+	if n := utf8.RuneCountInString(m.About); n > MaxBotAboutLength {
+		addf("about: длина %d, максимум %d символов", n, MaxBotAboutLength)
+	}
+
+	if len(m.Commands) > 0 {
+		if err := mahalo.ValidateCommandList(m.Commands); err != nil {
+			if ce, ok := err.(*mahalo.CommandsError); ok {
+				for _, v := range ce.Violations {
+					addf("commands: %s", v)
+				}
+			} else {
+				addf("commands: %v", err)
+			}
+		}
+	}
+
+	if m.Userpic != "" {
+		if fi, err := os.Stat(m.Userpic); err != nil {
+			addf("userpic: %v", err)
+		} else if fi.IsDir() {
+			addf("userpic: %s — каталог, а не файл", m.Userpic)
+		}
+	}
+
+	if m.Domain != "" {
+		if strings.Contains(m.Domain, "/") || !strings.Contains(m.Domain, ".") {
+			addf("domain: %q — ожидается доменное имя без схемы и пути", m.Domain)
+		}
+	}
+
+	if m.MenuButton != nil {
+		if strings.TrimSpace(m.MenuButton.Text) == "" {
+			addf("menu_button.text: пустой текст кнопки")
+		}
+		if u, err := url.Parse(m.MenuButton.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			addf("menu_button.url: %q — ожидается https-ссылка", m.MenuButton.URL)
+		}
+	}
+
+	if m.Webhook != nil {
+		problems = append(problems, m.Webhook.validate()...)
+	}
+	if m.AdminRights != nil {
+		problems = append(problems, m.AdminRights.validate()...)
+	}
+
+	for _, lang := range m.languages() {
+		problems = append(problems, m.Localized[lang].validate(lang)...)
+	}
+
+	seenScopes := map[string]bool{}
+	for _, sc := range m.ScopedCommands {
+		problems = append(problems, sc.validate()...)
+		if seenScopes[sc.field()] {
+			addf("%s: область и язык повторяются", sc.field())
+		}
+		seenScopes[sc.field()] = true
+	}
+
+	if len(problems) > 0 {
+		return &ManifestError{Problems: problems}
+	}
+	return nil
+}
@@ -1,12 +1,11 @@
 package ohana
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -25,8 +24,54 @@ var (
 
 // ========== ИНИЦИАЛИЗАЦИЯ ==========
 
+// Option — дополнительная настройка для SetupConfig
+type Option func(*Config)
+
+// WithPrompter задает ввод и вывод для интерактивных функций (по умолчанию — терминал)
+func WithPrompter(p Prompter) Option {
+	return func(c *Config) { c.Prompter = p }
+}
+
+// WithLogger задает логгер библиотеки. Без него библиотека ничего не пишет;
+// переписка с BotFather выводится на уровне Debug.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Config) { c.Logger = l }
+}
+
+// WithBotAPI задает адрес Bot API и HTTP-клиент для него, например для локального
+// сервера Bot API или httptest. Пустой baseURL и nil client оставляют значения по умолчанию.
+func WithBotAPI(baseURL string, client *http.Client) Option {
+	return func(c *Config) {
+		c.BotAPIURL = baseURL
+		c.HTTPClient = client
+	}
+}
+
+// WithRateLimit задает темп сообщений BotFather: не чаще одного в interval, до burst подряд.
+// По умолчанию — одно сообщение в секунду; interval 0 отключает ограничение.
+// Паузы по FLOOD_WAIT и просьбам BotFather подождать соблюдаются всегда.
+func WithRateLimit(interval time.Duration, burst int) Option {
+	return func(c *Config) {
+		c.MessageInterval = interval
+		c.MessageBurst = burst
+	}
+}
+
+// WithWaitCallback задает функцию, которая вызывается перед каждым ожиданием отправки
+// BotFather — например, чтобы показать пользователю, сколько осталось ждать
+func WithWaitCallback(fn func(mahalo.WaitEvent)) Option {
+	return func(c *Config) { c.OnWait = fn }
+}
+
+// WithMaxWait задает самое долгое ожидание по FLOOD_WAIT или просьбе BotFather подождать
+// (по умолчанию mahalo.DefaultMaxWait). Если нужно ждать дольше, операция прерывается
+// с *mahalo.WaitTooLongError. 0 — ждать сколько угодно.
+func WithMaxWait(d time.Duration) Option {
+	return func(c *Config) { c.MaxWait = d }
+}
+
 // SetupConfig сохраняет конфиг
-func SetupConfig(apiID int, apiHash, phone, sessionPath string) error {
+func SetupConfig(apiID int, apiHash, phone, sessionPath string, opts ...Option) error {
 	if sessionPath == "" {
 		sessionPath = "telegram_session.json"
 	}
@@ -36,8 +81,25 @@ func SetupConfig(apiID int, apiHash, phone, sessionPath string) error {
 		APIHash:     apiHash,
 		Phone:       phone,
 		SessionPath: sessionPath,
+
+		MessageInterval: mahalo.DefaultMessageInterval,
+		MessageBurst:    1,
+		MaxWait:         mahalo.DefaultMaxWait,
+	}
+	for _, opt := range opts {
+		opt(config)
 	}
 
+	// Один планировщик на аккаунт: все операции делят его темп и паузы
+	config.scheduler = mahalo.NewScheduler(config.MessageInterval, config.MessageBurst)
+	config.scheduler.OnWait = config.OnWait
+	config.scheduler.MaxWait = config.MaxWait
+
+	// Эти значения не должны попадать в логи и тексты ошибок
+	mahalo.RegisterSecret(apiHash)
+	mahalo.RegisterSecret(phone)
+	mahalo.RegisterSecret(strings.TrimPrefix(phone, "+"))
+
 	return nil
 }
 
@@ -45,128 +107,74 @@ func SetupConfig(apiID int, apiHash, phone, sessionPath string) error {
 
 // CreateBot создает нового бота с интерактивными повторными попытками
 func CreateBot(name string) (username, token string, err error) {
+	return CreateBotContext(context.Background(), name)
+}
+
+// CreateBotContext работает как CreateBot; отмена ctx прерывает диалог с BotFather
+func CreateBotContext(ctx context.Context, name string) (username, token string, err error) {
 	if config == nil {
-		return "", "", fmt.Errorf("конфиг не инициализирован")
+		return "", "", ErrNotConfigured
 	}
 
-	err = runClientWithAuthRetry(func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
-		log.Printf("✅ Клиент запущен")
-
+	err = runClientWithAuthRetry(startOperation(ctx, "create", ""), func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
 		botFather, err := mahalo.FindBotFather(ctx, api)
 		if err != nil {
-			log.Printf("❌ Ошибка при поиске BotFather: %v", err)
 			return fmt.Errorf("не удалось найти BotFather: %w", err)
 		}
-		log.Printf("✅ BotFather найден!")
-
-		// 1. Отправляем /newbot
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/newbot", 3); err != nil {
-			return err
-		}
-
-		// 2. Ждем запрос имени
-		_, err = mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a name", "how are we going to call", "alright, a new bot", "good. now let's choose"},
-			30*time.Second)
-		if err != nil {
-			return fmt.Errorf("ожидание запроса имени: %w", err)
-		}
+		defer func() {
+			if token == "" {
+				cancelNewBotDialogue(ctx, api, botFather)
+			}
+		}()
 
-		// 3. Отправляем имя бота
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, name, 3); err != nil {
+		// 1-4. Отправляем /newbot и имя, ждем запрос username
+		if err := startNewBotDialogue(ctx, api, botFather, name); err != nil {
 			return err
 		}
 
-		// 4. Ждем запрос username
-		_, err = mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a username", "username for your bot", "good. now let's choose"},
-			30*time.Second)
-		if err != nil {
-			return fmt.Errorf("ожидание запроса username: %w", err)
-		}
-
 		// 5. Интерактивная попытка username с повторами
+		p := prompter()
 		maxUsernameAttempts := 5
 		for attempt := 1; attempt <= maxUsernameAttempts; attempt++ {
-			fmt.Print("📝 Введите username для бота (должен заканчиваться на 'bot'): ")
-			reader := bufio.NewReader(os.Stdin)
-			userUsername, err := reader.ReadString('\n')
+			userUsername, err := p.Ask("📝 Введите username для бота (должен заканчиваться на 'bot'): ")
 			if err != nil {
 				return fmt.Errorf("ошибка при чтении username: %w", err)
 			}
-			userUsername = strings.TrimSpace(userUsername)
+			userUsername = mahalo.NormalizeBotUsername(userUsername)
 
 			// Валидация формата
-			if !strings.HasSuffix(strings.ToLower(userUsername), "bot") {
-				fmt.Printf("❌ Username должен заканчиваться на 'bot'\n")
+			if err := mahalo.ValidateBotUsername(userUsername); err != nil {
+				p.Show(fmt.Sprintf("❌ %v", err))
 				continue
 			}
 
-			username = userUsername
-
-			// Отправляем username
-			if err := mahalo.SendMessageWithRetry(ctx, api, botFather, username, 3); err != nil {
-				return err
-			}
-
-			// 6. Ждем ответ
-			resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-				[]string{"done", "congratulations", "use this token", "sorry", "invalid", "already taken"},
-				30*time.Second)
+			// BotFather всё ещё ждёт username, поэтому занятое имя просто спрашиваем заново
+			available, err := mahalo.CheckUsernameAvailable(ctx, api, userUsername)
 			if err != nil {
 				return err
 			}
+			if !available {
+				p.Show(fmt.Sprintf("❌ Username '@%s' уже занят (попытка %d/%d)", userUsername, attempt, maxUsernameAttempts))
+				continue
+			}
 
-			// Проверяем на ошибки
-			if err := mahalo.CheckBotFatherError(resp); err != nil {
+			// 6. Отправляем username и ждем ответ с токеном
+			token, err = submitBotUsername(ctx, api, botFather, userUsername)
+			if token != "" {
+				// Бот создан, даже если токен не удалось проверить или сохранить
+				username = userUsername
+			}
+			if err != nil {
 				if strings.Contains(err.Error(), mahalo.ErrUsernameTaken) {
-					fmt.Printf("❌ Username '@%s' уже занят (попытка %d/%d)\n", username, attempt, maxUsernameAttempts)
-					if attempt < maxUsernameAttempts {
-						fmt.Println("🔁 Попробуйте другой username...")
-						// Отправляем /newbot снова
-						if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/newbot", 3); err != nil {
-							return err
-						}
-						// Пропускаем запрос имени (он уже был)
-						// Отправляем имя снова
-						if err := mahalo.SendMessageWithRetry(ctx, api, botFather, name, 3); err != nil {
-							return err
-						}
-						// Ждем запрос username снова
-						if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-							[]string{"choose a username", "username for your bot", "good. now let's choose"},
-							30*time.Second); err != nil {
-							return err
-						}
-						continue
-					} else {
-						return fmt.Errorf("не удалось найти свободный username после %d попыток", maxUsernameAttempts)
-					}
+					// BotFather продолжает ждать username — просто спрашиваем следующий
+					p.Show(fmt.Sprintf("❌ Username '@%s' уже занят (попытка %d/%d)", userUsername, attempt, maxUsernameAttempts))
+					continue
 				}
 				return err
 			}
 
-			// Извлекаем токен
-			token = mahalo.ParseToken(resp)
-			if token == "" {
-				resp, err = mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-					[]string{"done", "congratulations", "use this token"},
-					10*time.Second)
-				if err != nil {
-					return fmt.Errorf("не удалось получить токен: %w", err)
-				}
-				token = mahalo.ParseToken(resp)
-				if token == "" {
-					return fmt.Errorf("не удалось извлечь токен из ответа BotFather")
-				}
-			}
-
-			fmt.Printf("✅ Бот @%s успешно создан!\n", username)
-			
-			// Пауза перед настройкой команд (BotFather может требовать времени)
-			log.Printf("⏳ Ожидание 5 сек перед дальнейшими операциями...")
-			time.Sleep(5 * time.Second)
-			
+			username = userUsername
+			p.Show(fmt.Sprintf("✅ Бот @%s успешно создан!", username))
 			return nil
 		}
 
@@ -178,69 +186,46 @@ func CreateBot(name string) (username, token string, err error) {
 
 // CreateBotWithUsername создает бота программно, принимает username (без @)
 func CreateBotWithUsername(name, userUsername string) (token string, err error) {
+	return CreateBotWithUsernameContext(context.Background(), name, userUsername)
+}
+
+// CreateBotWithUsernameContext работает как CreateBotWithUsername с контекстом ctx
+func CreateBotWithUsernameContext(ctx context.Context, name, userUsername string) (token string, err error) {
 	if config == nil {
-		return "", fmt.Errorf("конфиг не инициализирован")
+		return "", ErrNotConfigured
 	}
-	err = runClientWithAuthRetry(func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
-		botFather, err := mahalo.FindBotFather(ctx, api)
-		if err != nil {
-			return fmt.Errorf("не удалось найти BotFather: %w", err)
-		}
 
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/newbot", 3); err != nil {
-			return err
-		}
-
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a name", "how are we going to call", "alright, a new bot", "good. now let's choose"},
-			30*time.Second); err != nil {
-			return fmt.Errorf("ожидание запроса имени: %w", err)
-		}
+	userUsername = mahalo.NormalizeBotUsername(userUsername)
+	if err := mahalo.ValidateBotUsername(userUsername); err != nil {
+		return "", err
+	}
 
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, name, 3); err != nil {
+	err = runClientWithAuthRetry(startOperation(ctx, "create", userUsername), func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		// Занятое имя отсекаем локально, не расходуя лимиты BotFather
+		available, err := mahalo.CheckUsernameAvailable(ctx, api, userUsername)
+		if err != nil {
 			return err
 		}
-
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a username", "username for your bot", "good. now let's choose"},
-			30*time.Second); err != nil {
-			return fmt.Errorf("ожидание запроса username: %w", err)
-		}
-
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, userUsername, 3); err != nil {
-			return err
+		if !available {
+			return fmt.Errorf("%s: @%s", mahalo.ErrUsernameTaken, userUsername)
 		}
 
-		resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"done", "congratulations", "use this token", "sorry", "invalid", "already taken"},
-			30*time.Second)
+		botFather, err := mahalo.FindBotFather(ctx, api)
 		if err != nil {
-			return err
-		}
-
-		if err := mahalo.CheckBotFatherError(resp); err != nil {
-			return err
+			return fmt.Errorf("не удалось найти BotFather: %w", err)
 		}
-
-		token = mahalo.ParseToken(resp)
-		if token == "" {
-			resp, err = mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-				[]string{"done", "congratulations", "use this token"},
-				10*time.Second)
-			if err != nil {
-				return fmt.Errorf("не удалось получить токен: %w", err)
-			}
-			token = mahalo.ParseToken(resp)
+		defer func() {
 			if token == "" {
-				return fmt.Errorf("не удалось извлечь токен из ответа BotFather")
+				cancelNewBotDialogue(ctx, api, botFather)
 			}
-		}
+		}()
 
-		// Пауза перед настройкой команд (BotFather может требовать времени)
-		log.Printf("⏳ Ожидание 5 сек перед дальнейшими операциями...")
-		time.Sleep(5 * time.Second)
+		if err := startNewBotDialogue(ctx, api, botFather, name); err != nil {
+			return err
+		}
 
-		return nil
+		token, err = submitBotUsername(ctx, api, botFather, userUsername)
+		return err
 	})
 
 	return token, err
@@ -250,42 +235,95 @@ func CreateBotWithUsername(name, userUsername string) (token string, err error)
 // baseUsername - базовый кусок имени (может содержать 'bot' или не содержать)
 // maxAttempts - максимальное число попыток (включая первую)
 func CreateBotWithAutoUsername(name, baseUsername string, maxAttempts int) (chosenUsername, token string, err error) {
+	return CreateBotWithGeneratorContext(context.Background(), name, baseUsername, mahalo.NumericSuffix{}, maxAttempts)
+}
+
+// CreateBotWithAutoUsernameContext работает как CreateBotWithAutoUsername с контекстом ctx
+func CreateBotWithAutoUsernameContext(ctx context.Context, name, baseUsername string, maxAttempts int) (chosenUsername, token string, err error) {
+	return CreateBotWithGeneratorContext(ctx, name, baseUsername, mahalo.NumericSuffix{}, maxAttempts)
+}
+
+// CreateBotWithGenerator работает как CreateBotWithAutoUsername, но варианты username
+// выдает gen (встроенные стратегии — в пакете mahalo, можно передать свою)
+func CreateBotWithGenerator(name, baseUsername string, gen mahalo.UsernameGenerator, maxAttempts int) (chosenUsername, token string, err error) {
+	return CreateBotWithGeneratorContext(context.Background(), name, baseUsername, gen, maxAttempts)
+}
+
+// CreateBotWithGeneratorContext работает как CreateBotWithGenerator с контекстом ctx
+func CreateBotWithGeneratorContext(ctx context.Context, name, baseUsername string, gen mahalo.UsernameGenerator, maxAttempts int) (chosenUsername, token string, err error) {
+	if config == nil {
+		return "", "", ErrNotConfigured
+	}
 	if maxAttempts <= 0 {
 		maxAttempts = 5
 	}
 
 	// Нормализуем базу
-	base := strings.TrimSpace(baseUsername)
-	baseLower := strings.ToLower(base)
+	base := mahalo.NormalizeBotUsername(baseUsername)
+
+	err = runClientWithAuthRetry(startOperation(ctx, "create", ""), func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		chosenUsername, token, err = createBotWithGenerator(ctx, api, name, base, gen, maxAttempts)
+		return err
+	})
+
+	// При ошибке после создания бота username и токен тоже возвращаются
+	return chosenUsername, token, err
+}
+
+// createBotWithGenerator перебирает варианты username от gen в одном диалоге /newbot
+// в рамках уже открытого подключения
+func createBotWithGenerator(ctx context.Context, api *tg.Client, name, base string, gen mahalo.UsernameGenerator, maxAttempts int) (string, string, error) {
+	var (
+		botFather *tg.InputPeerUser
+		created   bool
+	)
+	// Не оставляем BotFather в ожидании username при любом исходе, кроме успеха
+	defer func() {
+		if botFather != nil && !created {
+			cancelNewBotDialogue(ctx, api, botFather)
+		}
+	}()
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		var candidate string
-		if attempt == 1 {
-			candidate = base
-		} else {
-			// Добавляем суффикс числа перед 'bot' если нужно, иначе просто добавляем число
-			if strings.HasSuffix(baseLower, "bot") {
-				// вставим число перед последним "bot"
-				idx := len(base) - 3
-				candidate = base[:idx] + strconv.Itoa(attempt) + base[idx:]
-			} else {
-				candidate = base + strconv.Itoa(attempt) + "bot"
-			}
+		// Некорректный кандидат не станет корректным на следующей попытке
+		candidate, err := gen.Candidate(base, attempt).Build()
+		if err != nil {
+			return "", "", err
+		}
+		if err := mahalo.ValidateBotUsername(candidate); err != nil {
+			return "", "", err
 		}
 
-		// Убедимся, что candidate оканчивается на 'bot'
-		if !strings.HasSuffix(strings.ToLower(candidate), "bot") {
-			candidate = candidate + "bot"
+		// Сначала проверяем имя локально — до BotFather доходят только вероятно свободные
+		available, err := mahalo.CheckUsernameAvailable(ctx, api, candidate)
+		if err != nil {
+			return "", "", err
+		}
+		if !available {
+			mahalo.Logger(ctx).Info("username занят, пропускаем", "candidate", candidate, "attempt", attempt, "max_attempts", maxAttempts)
+			continue
 		}
 
-		token, err = CreateBotWithUsername(name, candidate)
-		if err == nil {
-			return candidate, token, nil
+		// Диалог /newbot открываем один раз: после отказа BotFather продолжает ждать username
+		if botFather == nil {
+			if botFather, err = mahalo.FindBotFather(ctx, api); err != nil {
+				return "", "", fmt.Errorf("не удалось найти BotFather: %w", err)
+			}
+			if err := startNewBotDialogue(ctx, api, botFather, name); err != nil {
+				return "", "", err
+			}
+		}
+
+		token, err := submitBotUsername(ctx, api, botFather, candidate)
+		if token != "" {
+			// Бот создан; ошибка сохранения или проверки токена возвращается вместе с ним
+			created = true
+			return candidate, token, err
 		}
 
-		// Если username занят — пробуем дальше, иначе возвращаем ошибку
+		// Если username занят — отправляем следующий вариант в тот же диалог, иначе возвращаем ошибку
 		if strings.Contains(err.Error(), mahalo.ErrUsernameTaken) {
-			// continue
+			mahalo.Logger(ctx).Info("BotFather отклонил username, пробуем следующий", "candidate", candidate, "attempt", attempt, "max_attempts", maxAttempts)
 			continue
 		}
 		return "", "", err
@@ -294,111 +332,187 @@ func CreateBotWithAutoUsername(name, baseUsername string, maxAttempts int) (chos
 	return "", "", fmt.Errorf("не удалось найти свободный username после %d попыток", maxAttempts)
 }
 
-// Программные (неинтерактивные) функции настройки бота
+// cancelNewBotDialogue отправляет /cancel, чтобы BotFather не ждал имя или username
+// несозданного бота. Вызывается через defer, поэтому работает и после отмены ctx.
+func cancelNewBotDialogue(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/cancel", 3); err != nil {
+		mahalo.Logger(ctx).Warn("не удалось отменить диалог /newbot", "error", err)
+	}
+}
+
+// startNewBotDialogue начинает диалог /newbot и доводит его до запроса username
+func startNewBotDialogue(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, name string) error {
+	if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/newbot", 3); err != nil {
+		return err
+	}
+
+	if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"choose a name", "how are we going to call", "alright, a new bot", "good. now let's choose"},
+		30*time.Second); err != nil {
+		return fmt.Errorf("ожидание запроса имени: %w", err)
+	}
+
+	if err := mahalo.SendMessageWithRetry(ctx, api, botFather, name, 3); err != nil {
+		return err
+	}
+
+	if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"choose a username", "username for your bot", "good. now let's choose"},
+		30*time.Second); err != nil {
+		return fmt.Errorf("ожидание запроса username: %w", err)
+	}
+
+	return nil
+}
+
+// submitBotUsername отправляет username в открытый диалог /newbot и возвращает токен.
+// Если имя занято, BotFather ждёт следующий вариант, и диалог можно продолжать.
+// Непустой токен означает, что бот создан, даже если вместе с ним вернулась ошибка.
+func submitBotUsername(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, username string) (string, error) {
+	if err := mahalo.SendMessageWithRetry(ctx, api, botFather, username, 3); err != nil {
+		return "", err
+	}
+
+	resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"done", "congratulations", "use this token", "sorry", "invalid", "already taken"},
+		30*time.Second)
+	if err != nil {
+		return "", err
+	}
+
+	if err := mahalo.CheckBotFatherError(resp); err != nil {
+		return "", err
+	}
+
+	token := mahalo.ParseToken(resp)
+	if token == "" {
+		resp, err = mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+			[]string{"done", "congratulations", "use this token"},
+			10*time.Second)
+		if err != nil {
+			return "", fmt.Errorf("не удалось получить токен: %w", err)
+		}
+		token = mahalo.ParseToken(resp)
+		if token == "" {
+			return "", fmt.Errorf("не удалось извлечь токен из ответа BotFather")
+		}
+	}
+
+	// Пауза перед следующими сообщениями BotFather (ему может требоваться время);
+	// настройки через Bot API ее не ждут. Если MaxWait меньше паузы, она просто пропускается.
+	_ = mahalo.SchedulerFromContext(ctx).Block(5*time.Second, mahalo.WaitNewBot)
+
+	// Бот уже создан: токен возвращается и тогда, когда его не удалось проверить или сохранить
+	return token, acceptToken(ctx, username, token)
+}
+
+// Программные (неинтерактивные) функции настройки бота.
+// Варианты с суффиксом Context принимают контекст: его отмена прерывает диалог с BotFather.
 func SetBotName(botUsername, newName string) error {
-	return execBotFatherCommand(botUsername, "/setname", newName,
-		[]string{"send me the new name", "choose a name", "what name"},
-		[]string{"success", "updated", "done", "name updated"})
+	return SetBotNameContext(context.Background(), botUsername, newName)
+}
+
+func SetBotNameContext(ctx context.Context, botUsername, newName string) error {
+	return applySetting(ctx, botUsername, nameSetting(newName))
 }
 
 func SetBotDescription(botUsername, description string) error {
-	return execBotFatherCommand(botUsername, "/setdescription", description,
-		[]string{"send me the new description", "what description", "description for the bot"},
-		[]string{"success", "updated", "done", "description updated"})
+	return SetBotDescriptionContext(context.Background(), botUsername, description)
+}
+
+func SetBotDescriptionContext(ctx context.Context, botUsername, description string) error {
+	return applySetting(ctx, botUsername, descriptionSetting(description))
 }
 
 func SetBotAbout(botUsername, aboutText string) error {
-	return execBotFatherCommand(botUsername, "/setabouttext", aboutText,
-		[]string{"about", "send me", "new text", "about text"},
-		[]string{"success", "updated", "done", "about section updated"})
+	return SetBotAboutContext(context.Background(), botUsername, aboutText)
+}
+
+func SetBotAboutContext(ctx context.Context, botUsername, aboutText string) error {
+	return applySetting(ctx, botUsername, aboutSetting(aboutText))
 }
 
 func SetBotCommands(botUsername string, commands map[string]string) error {
-	commandsText := mahalo.FormatCommands(commands)
-	return execBotFatherCommand(botUsername, "/setcommands", commandsText,
-		[]string{"send me a list of commands", "list of commands", "command1 - description"},
-		[]string{"success", "updated", "done", "command list updated"})
+	return SetBotCommandList(botUsername, mahalo.CommandsFromMap(commands))
+}
+
+// SetBotCommandList устанавливает команды в заданном порядке
+func SetBotCommandList(botUsername string, commands []mahalo.BotCommand) error {
+	return SetBotCommandListContext(context.Background(), botUsername, commands)
+}
+
+func SetBotCommandListContext(ctx context.Context, botUsername string, commands []mahalo.BotCommand) error {
+	// Проверяем команды до разговора с BotFather, иначе ошибка превратится в таймаут
+	if err := mahalo.ValidateCommandList(commands); err != nil {
+		return err
+	}
+	return applySetting(ctx, botUsername, commandsSetting(commands))
 }
 
 func SetBotUserpic(botUsername, imagePath string) error {
-	return execBotFatherPhotoInteractive(botUsername, imagePath)
+	return SetBotUserpicContext(context.Background(), botUsername, imagePath)
+}
+
+func SetBotUserpicContext(ctx context.Context, botUsername, imagePath string) error {
+	return withBotFather(startOperation(ctx, "setuserpic", botUsername), func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		return setBotUserpic(ctx, api, botFather, botUsername, imagePath)
+	})
 }
 
 func DeleteBot(botUsername string) error {
-	return execBotFatherCommand(botUsername, "/deletebot", "Yes, I am totally sure.",
-		[]string{"are you sure", "confirm", "delete this bot", "yes, i am totally sure"},
-		[]string{"deleted", "successfully deleted", "bot has been deleted", "done", "bot is gone"})
+	return DeleteBotContext(context.Background(), botUsername)
+}
+
+func DeleteBotContext(ctx context.Context, botUsername string) error {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	if err := execBotFatherCommand(ctx, botUsername, deleteBotDialogue, deleteBotConfirmation); err != nil {
+		return err
+	}
+	// Токен удаленного бота больше не действует
+	forgetToken(botUsername)
+	return nil
 }
 
 // ========== ФУНКЦИИ НАСТРОЙКИ БОТА ==========
 
 // SetBotNameInteractive изменяет имя бота интерактивно
 func SetBotNameInteractive() error {
-	fmt.Print("📝 Введите username бота (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
-
-	fmt.Print("📝 Введите новое имя бота: ")
-	newName, _ := reader.ReadString('\n')
-	newName = strings.TrimSpace(newName)
-
-	return execBotFatherCommandInteractive(botUsername, "/setname", newName,
-		[]string{"send me the new name", "choose a name", "what name"},
-		[]string{"success", "updated", "done", "name updated"})
+	return setTextInteractive(nameSetting, "📝 Введите новое имя бота: ")
 }
 
 // SetBotDescriptionInteractive изменяет описание бота
 func SetBotDescriptionInteractive() error {
-	fmt.Print("📝 Введите username бота (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
-
-	fmt.Print("📝 Введите описание бота: ")
-	description, _ := reader.ReadString('\n')
-	description = strings.TrimSpace(description)
-
-	return execBotFatherCommandInteractive(botUsername, "/setdescription", description,
-		[]string{"send me the new description", "what description", "description for the bot"},
-		[]string{"success", "updated", "done", "description updated"})
+	return setTextInteractive(descriptionSetting, "📝 Введите описание бота: ")
 }
 
 // SetBotAboutInteractive изменяет информацию "О боте"
 func SetBotAboutInteractive() error {
-	fmt.Print("📝 Введите username бота (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
-
-	fmt.Print("📝 Введите информацию 'О боте': ")
-	aboutText, _ := reader.ReadString('\n')
-	aboutText = strings.TrimSpace(aboutText)
-
-	return execBotFatherCommandInteractive(botUsername, "/setabouttext", aboutText,
-		[]string{"about", "send me", "new text", "about text"},
-		[]string{"success", "updated", "done", "about section updated"})
+	return setTextInteractive(aboutSetting, "📝 Введите информацию 'О боте': ")
 }
 
 // SetBotCommandsInteractive устанавливает команды бота
 func SetBotCommandsInteractive() error {
-	fmt.Print("📝 Введите username бота (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
-
-	fmt.Println("📝 Введите команды (формат: команда - описание, без ведущего '/').")
-	fmt.Println("💡 Примеры:")
-	fmt.Println("  start - запустить бота")
-	fmt.Println("  help - получить помощь")
-	fmt.Println("  settings - настройки")
-	fmt.Println("(введите 'done' когда закончите)")
+	p := prompter()
+	botUsername, err := askBotUsername(p, "📝 Введите username бота (например: mybot): ")
+	if err != nil {
+		return err
+	}
+
+	p.Show("📝 Введите команды (формат: команда - описание, без ведущего '/').\n" +
+		"💡 Примеры:\n" +
+		"  start - запустить бота\n" +
+		"  help - получить помощь\n" +
+		"  settings - настройки\n" +
+		"(введите 'done' когда закончите)")
 
 	var commands []string
-	reader = bufio.NewReader(os.Stdin)
 	for {
-		line, _ := reader.ReadString('\n')
-		line = strings.TrimSpace(line)
+		line, err := p.Ask("")
+		if err != nil {
+			return err
+		}
 		if line == "done" {
 			break
 		}
@@ -412,50 +526,81 @@ func SetBotCommandsInteractive() error {
 	}
 
 	commandsText := strings.Join(commands, "\n")
-	return execBotFatherCommandInteractive(botUsername, "/setcommands", commandsText,
-		[]string{"send me a list of commands", "list of commands", "command1 - description"},
-		[]string{"success", "updated", "done", "command list updated"})
+	// Команды введены в формате BotFather, поэтому отправляем их диалогом
+	return execBotFatherCommandInteractive(botUsername, setting{dialogue: setCommandsDialogue, text: commandsText})
 }
 
 // SetBotUserpicInteractive устанавливает фото профиля бота
 func SetBotUserpicInteractive() error {
-	fmt.Print("📝 Введите username бота (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
+	p := prompter()
+	botUsername, err := askBotUsername(p, "📝 Введите username бота (например: mybot): ")
+	if err != nil {
+		return err
+	}
 
-	fmt.Print("📸 Введите путь к изображению профиля: ")
-	imagePath, _ := reader.ReadString('\n')
-	imagePath = strings.TrimSpace(imagePath)
+	imagePath, err := p.Ask("📸 Введите путь к изображению профиля: ")
+	if err != nil {
+		return err
+	}
 
 	return execBotFatherPhotoInteractive(botUsername, imagePath)
 }
 
 // DeleteBotInteractive удаляет бота
 func DeleteBotInteractive() error {
-	fmt.Print("📝 Введите username бота для удаления (например: mybot): ")
-	reader := bufio.NewReader(os.Stdin)
-	botUsername, _ := reader.ReadString('\n')
-	botUsername = strings.TrimSpace(botUsername)
-
-	fmt.Println("⚠️ Вы уверены, что хотите удалить этого бота? Введите 'yes' для подтверждения:")
-	confirmation, _ := reader.ReadString('\n')
-	confirmation = strings.TrimSpace(confirmation)
+	p := prompter()
+	botUsername, err := askBotUsername(p, "📝 Введите username бота для удаления (например: mybot): ")
+	if err != nil {
+		return err
+	}
 
-	if strings.ToLower(confirmation) != "yes" {
-		fmt.Println("❌ Отменено")
+	confirmed, err := p.Confirm("⚠️ Вы уверены, что хотите удалить этого бота?")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		p.Show("❌ Отменено")
 		return nil
 	}
 
-	return execBotFatherCommandInteractive(botUsername, "/deletebot", "Yes, I am totally sure.",
-		[]string{"are you sure", "confirm", "delete this bot", "yes, i am totally sure"},
-		[]string{"deleted", "successfully deleted", "bot has been deleted", "done", "bot is gone"})
+	if err := DeleteBot(botUsername); err != nil {
+		return err
+	}
+
+	p.Show(fmt.Sprintf("✅ Операция успешно выполнена для бота @%s", botUsername))
+	return nil
+}
+
+// setTextInteractive спрашивает username бота и текст и применяет настройку newSetting(text)
+func setTextInteractive(newSetting func(text string) setting, question string) error {
+	p := prompter()
+	botUsername, err := askBotUsername(p, "📝 Введите username бота (например: mybot): ")
+	if err != nil {
+		return err
+	}
+
+	text, err := p.Ask(question)
+	if err != nil {
+		return err
+	}
+
+	return execBotFatherCommandInteractive(botUsername, newSetting(text))
+}
+
+// askBotUsername спрашивает username бота и нормализует его
+func askBotUsername(p Prompter, question string) (string, error) {
+	botUsername, err := p.Ask(question)
+	if err != nil {
+		return "", err
+	}
+	return mahalo.NormalizeBotUsername(botUsername), nil
 }
 
 // ========== ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ ==========
 // authorize выполняет авторизацию если нужно
 func authorize(ctx context.Context, client *telegram.Client, api *tg.Client) error {
-	log.Printf("🔐 Проверка авторизации...")
+	logger := mahalo.Logger(ctx).With("step", "auth")
+	logger.Debug("проверка авторизации")
 
 	// Если файл сессии старше TTL, считаем его устаревшим и удаляем.
 	// Если файл есть и свежий — проверим его работоспособность выполнив маленький API вызов.
@@ -463,26 +608,26 @@ func authorize(ctx context.Context, client *telegram.Client, api *tg.Client) err
 	if config != nil {
 		if fi, err := os.Stat(config.SessionPath); err == nil {
 			if time.Since(fi.ModTime()) > sessionTTL {
-				log.Printf("⚠️ Файл сессии старше %v, удаляем: %s", sessionTTL, config.SessionPath)
+				logger.Warn("файл сессии устарел, удаляем", "ttl", sessionTTL, "session", config.SessionPath)
 				_ = os.Remove(config.SessionPath)
 			} else {
 				// Сессия недавняя — проверим валидность ключа
-				log.Printf("ℹ️ Сессия имеет возраст %v — проверяем её валидность", time.Since(fi.ModTime()))
+				logger.Debug("проверяем сессию", "age", time.Since(fi.ModTime()))
 				if api != nil {
 					// Небольшой вызов для проверки авторизации
 					if _, err := api.HelpGetConfig(ctx); err == nil {
-						log.Printf("✅ Сессия валидна (HelpGetConfig)")
+						logger.Debug("сессия валидна")
 						return nil
 					} else {
-						log.Printf("⚠️ Проверка сессии не удалась: %v", err)
+						logger.Warn("проверка сессии не удалась", "error", err)
 						// Если это ошибка, связанная с невалидным ключом — удаляем сессию и продолжим авторизацию
 						if strings.Contains(err.Error(), "AUTH_KEY_UNREGISTERED") || strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "Unauthorized") {
-							log.Printf("🔁 Сессия невалидна — удаляем файл сессии и повторяем авторизацию")
+							logger.Info("сессия невалидна, удаляем файл сессии и повторяем авторизацию")
 							_ = os.Remove(config.SessionPath)
 							// continue to auth flow below
 						} else {
 							// Для прочих ошибок попробуем всё равно пройти авторизацию (чтобы восстановить состояние)
-							log.Printf("ℹ️ Попробуем пройти поток авторизации несмотря на ошибку проверки")
+							logger.Debug("проходим авторизацию несмотря на ошибку проверки")
 						}
 					}
 				}
@@ -491,91 +636,97 @@ func authorize(ctx context.Context, client *telegram.Client, api *tg.Client) err
 	}
 
 	// Если мы здесь — выполняем стандартный поток авторизации
-	log.Printf("📱 Начинаем процесс авторизации для номера: %s", config.Phone)
+	logger.Info("начинаем авторизацию", "phone", config.Phone)
 	flow := auth.NewFlow(
 		auth.Constant(config.Phone, "", auth.CodeAuthenticatorFunc(
 			func(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
-				fmt.Println("\n📨 Код отправлен на Telegram!")
-				fmt.Print("📱 Введите код из Telegram: ")
-				reader := bufio.NewReader(os.Stdin)
-				code, err := reader.ReadString('\n')
-				if err != nil {
-					return "", err
-				}
-				return strings.TrimSpace(code), nil
+				p := prompter()
+				p.Show("\n📨 Код отправлен на Telegram!")
+				// Код не регистрируется как секрет: короткое число испортило бы
+				// маскирование других значений, а в текстах его ловит шаблон кода входа
+				return p.Ask("📱 Введите код из Telegram: ")
 			},
 		)),
 		auth.SendCodeOptions{},
 	)
 
 	if err := client.Auth().IfNecessary(ctx, flow); err != nil {
-		log.Printf("❌ Авторизация не удалась: %v", err)
-		return fmt.Errorf("авторизация не удалась: %w", err)
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
 	}
 
-	fmt.Println("✅ Успешно авторизованы!")
-	log.Printf("✅ Авторизация успешна")
+	logger.Info("авторизация успешна")
 	return nil
 }
 
-// execBotFatherCommand выполняет команду с BotFather
-func execBotFatherCommand(botUsername, command, text string, waitKeywords, successKeywords []string) error {
-	// Use the client-run wrapper that retries once on AUTH_KEY_UNREGISTERED
-	return runClientWithAuthRetry(func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
-		botFather, err := mahalo.FindBotFather(ctx, api)
-		if err != nil {
-			return fmt.Errorf("не удалось найти BotFather: %w", err)
-		}
-
-		// 1. Отправляем команду
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, command, 3); err != nil {
-			return err
-		}
+// configLogger возвращает логгер из конфига или логгер, который ничего не пишет
+func configLogger() *slog.Logger {
+	if config != nil && config.Logger != nil {
+		return config.Logger
+	}
+	return mahalo.Logger(context.Background())
+}
 
-		// 2. Ждем выбор бота
-		resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a bot", "select a bot", "which bot"},
-			30*time.Second)
-		if err != nil {
-			return fmt.Errorf("ожидание выбора бота: %w", err)
-		}
+type (
+	operationKey    struct{}
+	operationBotKey struct{}
+)
 
-		if strings.Contains(strings.ToLower(resp), "not found") ||
-			strings.Contains(strings.ToLower(resp), "no bot") {
-			return fmt.Errorf("бот @%s не найден", botUsername)
-		}
+// startOperation кладет в контекст логгер с атрибутами operation и bot.
+// Логгер, уже переданный в контексте, важнее логгера из конфига;
+// вложенные операции (ExportBot → ReadBotState) сохраняют атрибуты внешней.
+func startOperation(ctx context.Context, operation, botUsername string) context.Context {
+	if ctx.Value(operationKey{}) != nil {
+		return ctx
+	}
 
-		// 3. Отправляем username бота
-		botUsernameWithAt := "@" + botUsername
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, botUsernameWithAt, 3); err != nil {
-			return fmt.Errorf("не удалось отправить username бота: %w", err)
-		}
+	logger, ok := mahalo.LoggerFromContext(ctx)
+	if !ok {
+		logger = configLogger()
+	}
+	ctx = mahalo.WithLogger(context.WithValue(ctx, operationKey{}, operation), logger.With("operation", operation))
+	return withOperationBot(ctx, botUsername)
+}
 
-		// 4. Ждем запрос
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather, waitKeywords, 30*time.Second); err != nil {
-			return fmt.Errorf("ожидание запроса: %w", err)
-		}
+// withOperationBot добавляет атрибут bot к логгеру операции, если бот стал известен
+// только по ходу операции (автоподбор username, base_username). Уже заданный бот не меняется.
+func withOperationBot(ctx context.Context, botUsername string) context.Context {
+	if botUsername == "" || ctx.Value(operationBotKey{}) != nil {
+		return ctx
+	}
+	logger := mahalo.Logger(ctx).With("bot", botUsername)
+	return mahalo.WithLogger(context.WithValue(ctx, operationBotKey{}, botUsername), logger)
+}
 
-		// 5. Отправляем текст
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, text, 3); err != nil {
-			return fmt.Errorf("не удалось отправить текст: %w", err)
-		}
+// execBotFatherCommand выполняет команду с BotFather
+func execBotFatherCommand(ctx context.Context, botUsername string, dialogue botFatherDialogue, text string) error {
+	ctx = startOperation(ctx, strings.TrimPrefix(dialogue.command, "/"), botUsername)
+	return withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		return dialogue.run(ctx, api, botFather, botUsername, text)
+	})
+}
 
-		// 6. Ждем подтверждение
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather, successKeywords, 30*time.Second); err != nil {
-			return fmt.Errorf("ожидание подтверждения: %w", err)
+// withBotFather открывает подключение, находит BotFather и выполняет action
+func withBotFather(ctx context.Context, action func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error) error {
+	// Use the client-run wrapper that retries once on AUTH_KEY_UNREGISTERED
+	return runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		botFather, err := mahalo.FindBotFather(ctx, api)
+		if err != nil {
+			return fmt.Errorf("не удалось найти BotFather: %w", err)
 		}
-
-		return nil
+		return action(ctx, api, botFather)
 	})
 }
 
 // runClientWithAuthRetry запускает клиент и выполняет действие; при обнаружении AUTH_KEY_UNREGISTERED
 // удаляет файл сессии и повторяет один раз.
-func runClientWithAuthRetry(action func(ctx context.Context, api *tg.Client, client *telegram.Client) error) error {
+func runClientWithAuthRetry(ctx context.Context, action func(ctx context.Context, api *tg.Client, client *telegram.Client) error) error {
 	if config == nil {
-		return fmt.Errorf("конфиг не инициализирован")
+		return ErrNotConfigured
 	}
+	if _, ok := mahalo.LoggerFromContext(ctx); !ok {
+		ctx = mahalo.WithLogger(ctx, configLogger())
+	}
+	ctx = mahalo.WithScheduler(ctx, config.scheduler)
 
 	attempts := 0
 	for {
@@ -583,7 +734,6 @@ func runClientWithAuthRetry(action func(ctx context.Context, api *tg.Client, cli
 			SessionStorage: &session.FileStorage{Path: config.SessionPath},
 		})
 
-		ctx := context.Background()
 		err := client.Run(ctx, func(ctx context.Context) error {
 			api := client.API()
 			// authorize will re-auth if needed
@@ -599,122 +749,64 @@ func runClientWithAuthRetry(action func(ctx context.Context, api *tg.Client, cli
 
 		// Если получили AUTH_KEY_UNREGISTERED — попробуем удалить сессию и повторить один раз
 		if attempts == 0 && strings.Contains(err.Error(), "AUTH_KEY_UNREGISTERED") {
-			log.Printf("⚠️ Обнаружена AUTH_KEY_UNREGISTERED, удаляю сессию и повторяю: %v", err)
+			mahalo.Logger(ctx).Warn("AUTH_KEY_UNREGISTERED, удаляем сессию и повторяем", "error", err)
 			_ = os.Remove(config.SessionPath)
 			attempts++
 			continue
 		}
 
-		return err
+		// В ошибку мог попасть ответ BotFather с токеном или данные аккаунта
+		return mahalo.RedactError(err)
 	}
 }
 
-// execBotFatherCommandInteractive выполняет команду с BotFather с интерактивным вводом
-func execBotFatherCommandInteractive(botUsername, command, text string, waitKeywords, successKeywords []string) error {
-	return runClientWithAuthRetry(func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
-		botFather, err := mahalo.FindBotFather(ctx, api)
-		if err != nil {
-			return fmt.Errorf("не удалось найти BotFather: %w", err)
-		}
-
-		// 1. Отправляем команду
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, command, 3); err != nil {
-			return err
-		}
-
-		// 2. Ждем выбор бота
-		resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a bot", "select a bot", "which bot"},
-			30*time.Second)
-		if err != nil {
-			return fmt.Errorf("ожидание выбора бота: %w", err)
-		}
-
-		if strings.Contains(strings.ToLower(resp), "not found") ||
-			strings.Contains(strings.ToLower(resp), "no bot") {
-			return fmt.Errorf("бот @%s не найден", botUsername)
-		}
-
-		// 3. Отправляем username бота
-		botUsernameWithAt := "@" + botUsername
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, botUsernameWithAt, 3); err != nil {
-			return fmt.Errorf("не удалось отправить username бота: %w", err)
-		}
-
-		// 4. Ждем запрос
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather, waitKeywords, 30*time.Second); err != nil {
-			return fmt.Errorf("ожидание запроса: %w", err)
-		}
-
-		// 5. Отправляем текст
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, text, 3); err != nil {
-			return fmt.Errorf("не удалось отправить текст: %w", err)
-		}
-
-		// 6. Ждем подтверждение
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather, successKeywords, 30*time.Second); err != nil {
-			return fmt.Errorf("ожидание подтверждения: %w", err)
-		}
+// execBotFatherCommandInteractive применяет настройку и сообщает об успехе через Prompter
+func execBotFatherCommandInteractive(botUsername string, s setting) error {
+	if err := applySetting(context.Background(), botUsername, s); err != nil {
+		return err
+	}
 
-		fmt.Printf("✅ Операция успешно выполнена для бота @%s\n", botUsername)
-		return nil
-	})
+	prompter().Show(fmt.Sprintf("✅ Операция успешно выполнена для бота @%s", botUsername))
+	return nil
 }
 
 // execBotFatherPhotoInteractive отправляет фото бота через BotFather интерактивно
 func execBotFatherPhotoInteractive(botUsername, imagePath string) error {
-	return runClientWithAuthRetry(func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
-		botFather, err := mahalo.FindBotFather(ctx, api)
-		if err != nil {
-			return fmt.Errorf("не удалось найти BotFather: %w", err)
-		}
-
-		// 1. Отправляем /setuserpic
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, "/setuserpic", 3); err != nil {
-			return err
-		}
-
-		// 2. Ждем выбор бота
-		resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"choose a bot", "select a bot", "which bot"},
-			30*time.Second)
-		if err != nil {
-			return fmt.Errorf("ожидание выбора бота: %w", err)
-		}
+	if err := SetBotUserpic(botUsername, imagePath); err != nil {
+		return err
+	}
 
-		if strings.Contains(strings.ToLower(resp), "not found") ||
-			strings.Contains(strings.ToLower(resp), "no bot") {
-			return fmt.Errorf("бот @%s не найден", botUsername)
-		}
+	prompter().Show(fmt.Sprintf("✅ Фото профиля успешно установлено для бота @%s", botUsername))
+	return nil
+}
 
-		// 3. Отправляем username бота
-		botUsernameWithAt := "@" + botUsername
-		if err := mahalo.SendMessageWithRetry(ctx, api, botFather, botUsernameWithAt, 3); err != nil {
-			return fmt.Errorf("не удалось отправить username бота: %w", err)
-		}
+// setBotUserpic отправляет фото бота через BotFather в уже открытом подключении
+func setBotUserpic(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername, imagePath string) error {
+	// 1-3. Отправляем /setuserpic и выбираем бота
+	if err := mahalo.SelectBot(ctx, api, botFather, "/setuserpic", botUsername); err != nil {
+		return err
+	}
 
-		// 4. Ждем запрос фото
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"send me the new profile photo", "profile photo", "photo for the bot", "ok. send me"},
-			30*time.Second); err != nil {
-			return fmt.Errorf("ожидание запроса фото: %w", err)
-		}
+	// 4. Ждем запрос фото
+	if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"send me the new profile photo", "profile photo", "photo for the bot", "ok. send me"},
+		30*time.Second); err != nil {
+		return fmt.Errorf("ожидание запроса фото: %w", err)
+	}
 
-		// 5. Отправляем фото
-		if err := mahalo.SendPhoto(ctx, api, botFather, imagePath); err != nil {
-			return err
-		}
+	// 5. Отправляем фото
+	if err := mahalo.SendPhoto(ctx, api, botFather, imagePath); err != nil {
+		return err
+	}
 
-		// 6. Ждем подтверждение
-		if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
-			[]string{"success", "updated", "done", "photo updated"},
-			30*time.Second); err != nil {
-			return fmt.Errorf("ожидание подтверждения: %w", err)
-		}
+	// 6. Ждем подтверждение
+	if _, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"success", "updated", "done", "photo updated"},
+		30*time.Second); err != nil {
+		return fmt.Errorf("ожидание подтверждения: %w", err)
+	}
 
-		fmt.Printf("✅ Фото профиля успешно установлено для бота @%s\n", botUsername)
-		return nil
-	})
+	return nil
 }
 
 // Config содержит конфигурацию
@@ -723,4 +815,16 @@ type Config struct {
 	APIHash     string
 	Phone       string
 	SessionPath string
+	Prompter    Prompter     // nil — терминал
+	Logger      *slog.Logger // nil — без логов
+	TokenSink   TokenSink    // nil — токены только возвращаются
+	BotAPIURL   string       // адрес Bot API, "" — mahalo.DefaultBotAPIURL
+	HTTPClient  *http.Client // клиент для Bot API, nil — таймаут 30 секунд
+
+	MessageInterval time.Duration          // темп сообщений BotFather, см. WithRateLimit
+	MessageBurst    int                    // сколько сообщений можно отправить подряд
+	OnWait          func(mahalo.WaitEvent) // вызывается перед ожиданием отправки, nil — только лог
+	MaxWait         time.Duration          // дольше — *mahalo.WaitTooLongError, 0 — без ограничения
+
+	scheduler *mahalo.Scheduler
 }
@@ -0,0 +1,273 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// Change — одно расхождение между манифестом и текущим состоянием бота
+type Change struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// Plan — список изменений, которые нужно отправить BotFather, чтобы бот совпал с манифестом
+type Plan struct {
+	Manifest *Manifest `json:"-"`
+	Username string    `json:"username,omitempty"`
+	Create   bool      `json:"create"`
+	Changes  []Change  `json:"changes,omitempty"`
+	// Unverifiable — заданные поля, текущее значение которых прочитать нельзя (например, domain).
+	// Они не попадают в Changes и не применяются ApplyPlan.
+	Unverifiable []string `json:"unverifiable,omitempty"`
+	// Reapply — поля, которые сравнить нельзя, но ApplyPlan применяет всегда:
+	// вебхук с секретом и текст inline-подсказки, иначе их смена в манифесте
+	// никогда не дойдет до Telegram.
+	Reapply []string `json:"reapply,omitempty"`
+}
+
+// HasChanges сообщает, нужно ли что-то применять
+func (p *Plan) HasChanges() bool {
+	return p.Create || len(p.Changes) > 0 || len(p.Reapply) > 0
+}
+
+// Print выводит только изменения в читаемом виде
+func (p *Plan) Print(w io.Writer) {
+	switch {
+	case p.Create && p.Username != "":
+		fmt.Fprintf(w, "+ @%s будет создан и настроен по манифесту\n", p.Username)
+	case p.Create:
+		fmt.Fprintf(w, "+ бот %q будет создан с автоподбором username и настроен по манифесту\n", p.Manifest.Name)
+	case len(p.Changes) == 0:
+		fmt.Fprintf(w, "= @%s: изменений нет\n", p.Username)
+	default:
+		fmt.Fprintf(w, "~ @%s: изменений — %d\n", p.Username, len(p.Changes))
+		for _, c := range p.Changes {
+			fmt.Fprintf(w, "  ~ %s: %s → %s\n", c.Field, c.Current, c.Desired)
+		}
+	}
+	for _, field := range p.Unverifiable {
+		fmt.Fprintf(w, "  ? %s: текущее значение прочитать нельзя, пропущено\n", field)
+	}
+	for _, field := range p.Reapply {
+		fmt.Fprintf(w, "  ! %s: текущее значение прочитать нельзя, будет задано заново\n", field)
+	}
+}
+
+// PlanManifest сравнивает манифест с текущим состоянием бота, ничего не меняя
+func PlanManifest(ctx context.Context, m *Manifest) (*Plan, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx = startOperation(ctx, "plan", m.Username)
+	var plan *Plan
+	err := withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		var err error
+		plan, err = planManifest(ctx, api, botFather, m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planManifest строит план в уже открытом подключении
+func planManifest(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, m *Manifest) (*Plan, error) {
+	plan := &Plan{Manifest: m}
+	if m.BaseUsername != "" {
+		// Бот мог быть создан прошлым применением этого манифеста
+		existing, err := findOwnedCandidate(ctx, api, mahalo.NormalizeBotUsername(m.BaseUsername))
+		if err != nil {
+			return nil, err
+		}
+		if existing == "" {
+			plan.Create = true
+			return plan, nil
+		}
+		plan.Username = existing
+	} else {
+		plan.Username = mahalo.NormalizeBotUsername(m.Username)
+		bot, err := resolveOwnedBot(ctx, api, plan.Username)
+		if err != nil {
+			return nil, err
+		}
+		if bot == nil {
+			plan.Create = true
+			return plan, nil
+		}
+	}
+
+	state, err := readBotState(ctx, api, botFather, plan.Username, m.languages(), m.commandScopes())
+	if err != nil {
+		return nil, err
+	}
+	plan.Changes, plan.Unverifiable, plan.Reapply = diffManifest(m, state)
+	return plan, nil
+}
+
+// ApplyPlan применяет только изменения из плана; если бот должен быть создан,
+// манифест применяется целиком
+func ApplyPlan(ctx context.Context, p *Plan) (*ApplyResult, error) {
+	if p.Create {
+		return ApplyManifest(ctx, p.Manifest)
+	}
+
+	result := &ApplyResult{Username: p.Username}
+	if len(p.Changes) == 0 && len(p.Reapply) == 0 {
+		return result, nil
+	}
+
+	changed := make(map[string]bool, len(p.Changes)+len(p.Reapply))
+	for _, c := range p.Changes {
+		changed[c.Field] = true
+	}
+	for _, field := range p.Reapply {
+		changed[field] = true
+	}
+
+	var err error
+	result.Applied, err = applyFields(ctx, p.Manifest, p.Username, func(field string) bool { return changed[field] })
+	return result, err
+}
+
+// applyFields применяет поля манифеста, для которых include возвращает true,
+// к существующему боту в одном подключении. Возвращает примененные поля.
+func applyFields(ctx context.Context, m *Manifest, botUsername string, include func(field string) bool) ([]string, error) {
+	ctx = startOperation(ctx, "apply", botUsername)
+	var applied []string
+	err := runClientWithAuthRetry(ctx, func(ctx context.Context, api *tg.Client, client *telegram.Client) error {
+		botFather, err := mahalo.FindBotFather(ctx, api)
+		if err != nil {
+			return fmt.Errorf("не удалось найти BotFather: %w", err)
+		}
+
+		for _, field := range m.fields() {
+			if !include(field.name) {
+				continue
+			}
+			mahalo.Logger(ctx).Info("применяем поле", "step", field.name)
+			if err := field.apply(ctx, api, botFather, botUsername); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+			applied = append(applied, field.name)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// diffManifest возвращает расхождения по полям, заданным в манифесте.
+// Поля, которые нельзя прочитать, возвращаются отдельными списками: пропускаемые
+// и применяемые всегда.
+func diffManifest(m *Manifest, s *BotState) (changes []Change, unverifiable, reapply []string) {
+	add := func(field, current, desired string) {
+		if current != desired {
+			changes = append(changes, Change{Field: field, Current: current, Desired: desired})
+		}
+	}
+
+	if m.Name != "" {
+		add("name", strconv.Quote(s.Name), strconv.Quote(m.Name))
+	}
+	if m.Description != "" {
+		add("description", strconv.Quote(s.Description), strconv.Quote(m.Description))
+	}
+	if m.About != "" {
+		add("about", strconv.Quote(s.About), strconv.Quote(m.About))
+	}
+	if len(m.Commands) > 0 {
+		add("commands", formatCommandsInline(s.Commands), formatCommandsInline(m.Commands))
+	}
+	// Содержимое фото сравнить нельзя — проверяем только, что оно есть
+	if m.Userpic != "" && !s.HasUserpic {
+		add("userpic", "нет", m.Userpic)
+	}
+	if set := m.Settings; set != nil {
+		if set.Privacy != nil {
+			add("privacy", strconv.FormatBool(s.Privacy), strconv.FormatBool(*set.Privacy))
+		}
+		if set.JoinGroups != nil {
+			add("join_groups", strconv.FormatBool(s.JoinGroups), strconv.FormatBool(*set.JoinGroups))
+		}
+		// Текст подсказки Bot API не отдает: если inline-режим уже включен,
+		// задаем подсказку заново, иначе ее смена в манифесте не применится
+		if set.InlinePlaceholder != "" {
+			if s.Inline {
+				reapply = append(reapply, "inline_placeholder")
+			} else {
+				add("inline_placeholder", "inline выключен", strconv.Quote(set.InlinePlaceholder))
+			}
+		}
+	}
+	if m.Domain != "" {
+		unverifiable = append(unverifiable, "domain")
+	}
+	if m.MenuButton != nil {
+		add("menu_button", formatMenuButton(s.MenuButton), formatMenuButton(m.MenuButton))
+	}
+	if w := m.Webhook; w != nil {
+		current, desired := formatWebhook(s.Webhook, w), formatWebhook(w, w)
+		add("webhook", current, desired)
+		// Секрет Bot API не отдает: без повторной установки его смена не применится
+		if w.SecretToken != "" && current == desired {
+			reapply = append(reapply, "webhook")
+		}
+	}
+	if a := m.AdminRights; a != nil {
+		for _, forChannels := range []bool{false, true} {
+			have := s.GroupAdminRights
+			if forChannels {
+				have = s.ChannelAdminRights
+			}
+			if want, ok, _ := a.parsed(forChannels); ok {
+				add(adminRightsField(forChannels), have.String(), want.String())
+			}
+		}
+	}
+	for _, lang := range m.languages() {
+		want, have := m.Localized[lang], s.Localized[lang]
+		if want.Name != "" {
+			add(localizedField(lang, "name"), strconv.Quote(have.Name), strconv.Quote(want.Name))
+		}
+		if want.Description != "" {
+			add(localizedField(lang, "description"), strconv.Quote(have.Description), strconv.Quote(want.Description))
+		}
+		if want.About != "" {
+			add(localizedField(lang, "about"), strconv.Quote(have.About), strconv.Quote(want.About))
+		}
+		if len(want.Commands) > 0 {
+			add(localizedField(lang, "commands"), formatCommandsInline(have.Commands), formatCommandsInline(want.Commands))
+		}
+	}
+	for _, sc := range m.ScopedCommands {
+		add(sc.field(), formatCommandsInline(findScopedCommands(s.ScopedCommands, sc.field())), formatCommandsInline(sc.Commands))
+	}
+
+	return changes, unverifiable, reapply
+}
+
+// formatCommandsInline приводит команды к сравнимой однострочной форме
+func formatCommandsInline(commands []mahalo.BotCommand) string {
+	if len(commands) == 0 {
+		return "[]"
+	}
+	return "[" + strings.ReplaceAll(mahalo.FormatCommandList(commands), "\n", "; ") + "]"
+}
+
+func formatMenuButton(b *MenuButton) string {
+	if b == nil {
+		return "нет"
+	}
+	return fmt.Sprintf("%q → %s", b.Text, b.URL)
+}
@@ -0,0 +1,137 @@
+package ohana
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/tg"
+)
+
+// setting — изменение профиля бота, которое можно сделать через Bot API.
+// Bot API используется, когда токен бота известен; иначе — диалог BotFather.
+// Тексты для отдельного языка и команды для отдельных областей BotFather задать не умеет,
+// для них токен запрашивается через /token.
+type setting struct {
+	dialogue botFatherDialogue
+	name     string // шаг в логах для настроек без диалога BotFather
+	text     string // текст для BotFather
+	lang     string // код языка, "" — язык по умолчанию
+	botAPI   func(ctx context.Context, bot *mahalo.BotAPI, lang string) error
+}
+
+func nameSetting(name string) setting {
+	return setting{dialogue: setNameDialogue, text: name,
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, lang string) error {
+			return bot.SetMyName(ctx, name, lang)
+		}}
+}
+
+func descriptionSetting(description string) setting {
+	return setting{dialogue: setDescriptionDialogue, text: description,
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, lang string) error {
+			return bot.SetMyDescription(ctx, description, lang)
+		}}
+}
+
+func aboutSetting(about string) setting {
+	return setting{dialogue: setAboutDialogue, text: about,
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, lang string) error {
+			return bot.SetMyShortDescription(ctx, about, lang)
+		}}
+}
+
+func commandsSetting(commands []mahalo.BotCommand) setting {
+	return setting{dialogue: setCommandsDialogue, text: mahalo.FormatCommandList(commands),
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, lang string) error {
+			return bot.SetMyCommands(ctx, commands, lang)
+		}}
+}
+
+// viaBotFather сообщает, что без токена настройку можно отправить BotFather.
+// Тексты для отдельного языка и настройки без диалога задаются только через Bot API.
+func (s setting) viaBotFather() bool {
+	return s.lang == "" && s.dialogue.command != ""
+}
+
+// step возвращает имя настройки для логов
+func (s setting) step() string {
+	if s.name != "" {
+		return s.name
+	}
+	return strings.TrimPrefix(s.dialogue.command, "/")
+}
+
+// inLanguage возвращает ту же настройку для языка lang
+func (s setting) inLanguage(lang string) setting {
+	s.lang = lang
+	return s
+}
+
+// apply применяет настройку в уже открытом подключении
+func (s setting) apply(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) error {
+	if done, err := s.tryBotAPI(ctx, botUsername); done {
+		return err
+	}
+	if s.viaBotFather() {
+		return s.dialogue.run(ctx, api, botFather, botUsername, s.text)
+	}
+
+	token, err := getBotToken(ctx, api, botFather, botUsername)
+	if err != nil {
+		return err
+	}
+	return s.botAPI(ctx, newBotAPI(token), s.lang)
+}
+
+// tryBotAPI применяет настройку через Bot API. done=false означает, что токен неизвестен
+// или больше не действует, и настройку нужно отправить BotFather.
+func (s setting) tryBotAPI(ctx context.Context, botUsername string) (done bool, err error) {
+	token, ok := cachedToken(botUsername)
+	if !ok || s.botAPI == nil {
+		return false, nil
+	}
+
+	err = s.botAPI(ctx, newBotAPI(token), s.lang)
+	var apiErr *mahalo.BotAPIError
+	if errors.As(err, &apiErr) && (apiErr.Code == 401 || apiErr.Code == 404) {
+		// Токен отозван — забываем его и идем через BotFather
+		mahalo.Logger(ctx).Warn("токен не принят Bot API, используем BotFather", "error", err)
+		forgetToken(botUsername)
+		return false, nil
+	}
+	mahalo.Logger(ctx).Debug("настройка применена через Bot API", "step", s.step(), "lang", s.lang)
+	return true, err
+}
+
+// applySetting применяет настройку отдельной операцией: через Bot API — без подключения
+// к Telegram, иначе через execBotFatherCommand
+func applySetting(ctx context.Context, botUsername string, s setting) error {
+	ctx = startOperation(ctx, s.step(), botUsername)
+	if done, err := s.tryBotAPI(ctx, botUsername); done {
+		return err
+	}
+	if !s.viaBotFather() {
+		return withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+			return s.apply(ctx, api, botFather, botUsername)
+		})
+	}
+	return execBotFatherCommand(ctx, botUsername, s.dialogue, s.text)
+}
+
+// withBotAPI вызывает fn с клиентом Bot API бота. Токен берется из кэша, а если его нет
+// или Bot API его не принял — у BotFather через /token.
+func withBotAPI(ctx context.Context, botUsername string, fn func(ctx context.Context, bot *mahalo.BotAPI) error) error {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	s := setting{name: operation, botAPI: func(ctx context.Context, bot *mahalo.BotAPI, _ string) error {
+		return fn(ctx, bot)
+	}}
+	if done, err := s.tryBotAPI(ctx, botUsername); done {
+		return err
+	}
+	return withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		return s.apply(ctx, api, botFather, botUsername)
+	})
+}
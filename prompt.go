@@ -0,0 +1,102 @@
+package ohana
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Prompter — ввод и вывод интерактивных функций: CreateBot, *Interactive и запрос кода при входе
+type Prompter interface {
+	// Ask задает вопрос и возвращает ответ без пробелов по краям
+	Ask(question string) (string, error)
+	// Confirm задает вопрос с ответом да/нет
+	Confirm(question string) (bool, error)
+	// Show выводит сообщение пользователю
+	Show(message string)
+}
+
+// TerminalPrompter читает ответы из In и пишет вопросы в Out
+type TerminalPrompter struct {
+	In  *bufio.Reader
+	Out io.Writer
+}
+
+// NewTerminalPrompter создает Prompter для терминала. Вопросы пишутся в stderr,
+// чтобы stdout оставался для результатов.
+func NewTerminalPrompter() *TerminalPrompter {
+	return &TerminalPrompter{In: bufio.NewReader(os.Stdin), Out: os.Stderr}
+}
+
+func (p *TerminalPrompter) Ask(question string) (string, error) {
+	fmt.Fprint(p.Out, question)
+	line, err := p.In.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("ошибка при чтении ответа: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (p *TerminalPrompter) Confirm(question string) (bool, error) {
+	answer, err := p.Ask(question + " (yes/no): ")
+	if err != nil {
+		return false, err
+	}
+	return isYes(answer), nil
+}
+
+func (p *TerminalPrompter) Show(message string) {
+	fmt.Fprintln(p.Out, message)
+}
+
+// ScriptedPrompter отвечает заранее заданными ответами по порядку
+// и запоминает вопросы и сообщения — для тестов и автоматизации
+type ScriptedPrompter struct {
+	Answers   []string // ответы на Ask и Confirm ("yes"/"y"/"да" — согласие)
+	Questions []string
+	Messages  []string
+}
+
+func (p *ScriptedPrompter) Ask(question string) (string, error) {
+	p.Questions = append(p.Questions, question)
+	if len(p.Answers) == 0 {
+		return "", fmt.Errorf("нет заготовленного ответа на вопрос %q", question)
+	}
+	answer := strings.TrimSpace(p.Answers[0])
+	p.Answers = p.Answers[1:]
+	return answer, nil
+}
+
+func (p *ScriptedPrompter) Confirm(question string) (bool, error) {
+	answer, err := p.Ask(question)
+	if err != nil {
+		return false, err
+	}
+	return isYes(answer), nil
+}
+
+func (p *ScriptedPrompter) Show(message string) {
+	p.Messages = append(p.Messages, message)
+}
+
+func isYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "yes", "y", "да":
+		return true
+	}
+	return false
+}
+
+// Один терминальный Prompter на процесс: буфер чтения stdin не должен теряться между вопросами
+var terminalPrompter = sync.OnceValue(func() Prompter { return NewTerminalPrompter() })
+
+// prompter возвращает Prompter из конфига или терминальный по умолчанию
+func prompter() Prompter {
+	if config != nil && config.Prompter != nil {
+		return config.Prompter
+	}
+	return terminalPrompter()
+}
@@ -0,0 +1,141 @@
+package ohana
+
+import (
+	"context"
+	"time"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// Статусы Result
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Result — итог одной операции в виде, удобном для автоматизации; в таком же виде
+// его печатает командная строка с -output json. Для основных операций есть
+// варианты ...Result, для остальных — RunResult.
+type Result struct {
+	Operation  string `json:"operation"`
+	Username   string `json:"username,omitempty"`
+	Token      string `json:"token,omitempty"`
+	Status     string `json:"status"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Data       any    `json:"data,omitempty"` // данные конкретной операции: план, список ботов и т.п.
+
+	started time.Time
+}
+
+// NewResult начинает отсчет времени операции
+func NewResult(operation string) *Result {
+	return &Result{Operation: operation, started: time.Now()}
+}
+
+// Finish фиксирует длительность и статус операции по err
+func (r *Result) Finish(err error) *Result {
+	r.DurationMS = time.Since(r.started).Milliseconds()
+	r.Status = StatusOK
+	if err != nil {
+		r.Status = StatusFailed
+		r.ErrorCode = ErrorCode(err)
+		r.Error = mahalo.Redact(err.Error())
+	}
+	return r
+}
+
+// RunResult выполняет fn и возвращает ее итог как Result. fn заполняет Username,
+// Token и Data; статус, код ошибки и длительность выставляются по возвращенной ошибке:
+//
+//	res := ohana.RunResult("set name", func(res *ohana.Result) error {
+//		res.Username = "mybot"
+//		return ohana.SetBotNameContext(ctx, "mybot", "Shop")
+//	})
+func RunResult(operation string, fn func(res *Result) error) *Result {
+	res := NewResult(operation)
+	return res.Finish(fn(res))
+}
+
+// LoginResult работает как Login, но возвращает Result
+func LoginResult(ctx context.Context) *Result {
+	return RunResult("login", func(*Result) error { return Login(ctx) })
+}
+
+// CreateBotWithUsernameResult работает как CreateBotWithUsernameContext, но возвращает Result
+func CreateBotWithUsernameResult(ctx context.Context, name, userUsername string) *Result {
+	return RunResult("create", func(res *Result) (err error) {
+		res.Username = mahalo.NormalizeBotUsername(userUsername)
+		res.Token, err = CreateBotWithUsernameContext(ctx, name, res.Username)
+		return err
+	})
+}
+
+// CreateBotWithAutoUsernameResult работает как CreateBotWithAutoUsernameContext, но возвращает Result
+func CreateBotWithAutoUsernameResult(ctx context.Context, name, baseUsername string, maxAttempts int) *Result {
+	return RunResult("create", func(res *Result) (err error) {
+		res.Username, res.Token, err = CreateBotWithAutoUsernameContext(ctx, name, baseUsername, maxAttempts)
+		return err
+	})
+}
+
+// GetBotTokenResult работает как GetBotTokenContext, но возвращает Result
+func GetBotTokenResult(ctx context.Context, botUsername string) *Result {
+	return RunResult("token", func(res *Result) (err error) {
+		res.Username = mahalo.NormalizeBotUsername(botUsername)
+		res.Token, err = GetBotTokenContext(ctx, res.Username)
+		return err
+	})
+}
+
+// RevokeBotTokenResult работает как RevokeBotToken, но возвращает Result
+func RevokeBotTokenResult(ctx context.Context, botUsername string) *Result {
+	return RunResult("revoke", func(res *Result) (err error) {
+		res.Username = mahalo.NormalizeBotUsername(botUsername)
+		res.Token, err = RevokeBotToken(ctx, res.Username)
+		return err
+	})
+}
+
+// DeleteBotResult работает как DeleteBotContext, но возвращает Result
+func DeleteBotResult(ctx context.Context, botUsername string) *Result {
+	return RunResult("delete", func(res *Result) error {
+		res.Username = mahalo.NormalizeBotUsername(botUsername)
+		return DeleteBotContext(ctx, res.Username)
+	})
+}
+
+// ListBotsResult работает как ListBots, но возвращает Result со списком ботов в Data
+func ListBotsResult(ctx context.Context) *Result {
+	return RunResult("list", func(res *Result) error {
+		bots, err := ListBots(ctx)
+		if bots == nil {
+			bots = []string{}
+		}
+		res.Data = bots
+		return err
+	})
+}
+
+// ApplyManifestResult работает как ApplyManifest, но возвращает Result с *ApplyResult в Data
+func ApplyManifestResult(ctx context.Context, m *Manifest) *Result {
+	return RunResult("apply", func(res *Result) error {
+		applied, err := ApplyManifest(ctx, m)
+		if applied != nil {
+			res.Username, res.Token, res.Data = applied.Username, applied.Token, applied
+		}
+		return err
+	})
+}
+
+// DetectDriftResult работает как DetectDrift, но возвращает Result с *DriftReport в Data
+func DetectDriftResult(ctx context.Context, dir string) *Result {
+	return RunResult("drift", func(res *Result) error {
+		report, err := DetectDrift(ctx, dir)
+		if report != nil {
+			res.Data = report
+		}
+		return err
+	})
+}
@@ -0,0 +1,69 @@
+package ohana
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+func TestRunResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantCode   string
+	}{
+		{"успех", nil, StatusOK, ""},
+		{"username занят", errors.New(mahalo.ErrUsernameTaken), StatusFailed, CodeUsernameTaken},
+		{"некорректный username", &mahalo.UsernameError{Username: "x", Reason: "короткий"}, StatusFailed, CodeInvalidUsername},
+		{"токен в тексте ошибки маскируется", errors.New("ошибка для 1234567:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw"), StatusFailed, CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := RunResult("create", func(res *Result) error {
+				res.Username, res.Token = "shopbot", "1:a"
+				return tt.err
+			})
+			if res.Operation != "create" || res.Username != "shopbot" || res.Token != "1:a" {
+				t.Errorf("поля не сохранились: %+v", res)
+			}
+			if res.Status != tt.wantStatus || res.ErrorCode != tt.wantCode {
+				t.Errorf("статус %q, код %q; ожидалось %q, %q", res.Status, res.ErrorCode, tt.wantStatus, tt.wantCode)
+			}
+			if strings.Contains(res.Error, "AAHdq") {
+				t.Errorf("токен попал в Error: %q", res.Error)
+			}
+		})
+	}
+}
+
+func TestResultVariantsNotConfigured(t *testing.T) {
+	prev := config
+	config = nil
+	t.Cleanup(func() { config = prev })
+
+	ctx := context.Background()
+	for _, res := range []*Result{
+		LoginResult(ctx),
+		CreateBotWithUsernameResult(ctx, "Shop", "@ShopBot"),
+		CreateBotWithAutoUsernameResult(ctx, "Shop", "shop", 3),
+		GetBotTokenResult(ctx, "t.me/shopbot"),
+		DeleteBotResult(ctx, "shopbot"),
+		ListBotsResult(ctx),
+	} {
+		if res.Status != StatusFailed || res.ErrorCode != CodeNotConfigured {
+			t.Errorf("%s: статус %q, код %q; ожидалось %q, %q", res.Operation, res.Status, res.ErrorCode, StatusFailed, CodeNotConfigured)
+		}
+	}
+
+	if res := GetBotTokenResult(ctx, "t.me/shopbot"); res.Username != "shopbot" {
+		t.Errorf("username %q, ожидался нормализованный %q", res.Username, "shopbot")
+	}
+	if res := ListBotsResult(ctx); res.Data == nil {
+		t.Error("список ботов должен быть пустым, а не nil: в JSON это [], а не отсутствующее поле")
+	}
+}
@@ -0,0 +1,88 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// AdminRights — права, которые Telegram предлагает выдать боту при добавлении администратором.
+// Права перечисляются именами Bot API (can_delete_messages, can_pin_messages...).
+// nil — не трогать, пустой список — сбросить предложение.
+type AdminRights struct {
+	Groups   []string `json:"groups,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+// SetDefaultAdminRights задает права, предлагаемые при добавлении бота администратором
+// в группу (forChannels=false) или канал (forChannels=true), через Bot API.
+// Если токен бота неизвестен, он запрашивается у BotFather через /token.
+func SetDefaultAdminRights(ctx context.Context, botUsername string, forChannels bool, rights mahalo.ChatAdministratorRights) error {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "set_admin_rights", botUsername)
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		return bot.SetMyDefaultAdministratorRights(ctx, rights, forChannels)
+	})
+}
+
+// GetDefaultAdminRights возвращает права, предлагаемые при добавлении бота в группу или канал
+func GetDefaultAdminRights(ctx context.Context, botUsername string, forChannels bool) (mahalo.ChatAdministratorRights, error) {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "get_admin_rights", botUsername)
+	var rights mahalo.ChatAdministratorRights
+	err := withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		var err error
+		rights, err = bot.GetMyDefaultAdministratorRights(ctx, forChannels)
+		return err
+	})
+	return rights, err
+}
+
+// adminRightsField — имя поля манифеста для групп или каналов
+func adminRightsField(forChannels bool) string {
+	if forChannels {
+		return "admin_rights.channels"
+	}
+	return "admin_rights.groups"
+}
+
+// adminRightsSetting возвращает настройку, которая применяется только через Bot API
+func adminRightsSetting(rights mahalo.ChatAdministratorRights, forChannels bool) setting {
+	return setting{name: adminRightsField(forChannels), text: rights.String(),
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, _ string) error {
+			return bot.SetMyDefaultAdministratorRights(ctx, rights, forChannels)
+		}}
+}
+
+// parsed возвращает права для групп или каналов; ok=false — в манифесте они не заданы
+func (a *AdminRights) parsed(forChannels bool) (rights mahalo.ChatAdministratorRights, ok bool, err error) {
+	names := a.Groups
+	if forChannels {
+		names = a.Channels
+	}
+	if names == nil {
+		return rights, false, nil
+	}
+	rights, err = mahalo.ParseAdministratorRights(names)
+	return rights, true, err
+}
+
+// validate проверяет имена прав
+func (a *AdminRights) validate() []string {
+	var problems []string
+	for _, forChannels := range []bool{false, true} {
+		if _, _, err := a.parsed(forChannels); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", adminRightsField(forChannels), err))
+		}
+	}
+	return problems
+}
+
+// adminRightsState переводит прочитанные права в вид манифеста; nil — предложений нет
+func adminRightsState(groups, channels mahalo.ChatAdministratorRights) *AdminRights {
+	if groups.IsZero() && channels.IsZero() {
+		return nil
+	}
+	return &AdminRights{Groups: groups.Enabled(), Channels: channels.Enabled()}
+}
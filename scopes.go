@@ -0,0 +1,144 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// ScopedCommands — список команд для одной области видимости и, при необходимости, одного языка.
+// Например, отдельное меню для администраторов групп или для личных чатов.
+type ScopedCommands struct {
+	Scope    mahalo.BotCommandScope `json:"scope"`
+	Language string                 `json:"language,omitempty"` // код ISO 639-1, "" — все языки
+	Commands []mahalo.BotCommand    `json:"commands"`
+}
+
+// commandScope — область видимости и язык, для которых читаются команды
+type commandScope struct {
+	scope mahalo.BotCommandScope
+	lang  string
+}
+
+// SetCommands задает команды бота для области scope и языка languageCode ("" — все языки)
+// через Bot API. Если токен бота неизвестен, он запрашивается у BotFather через /token.
+func SetCommands(ctx context.Context, botUsername string, scope mahalo.BotCommandScope, languageCode string, commands []mahalo.BotCommand) error {
+	sc := ScopedCommands{Scope: scope, Language: languageCode, Commands: commands}
+	if problems := sc.validate(); len(problems) > 0 {
+		return &ManifestError{Problems: problems}
+	}
+
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "set_commands", botUsername)
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		return bot.SetMyCommandsInScope(ctx, commands, scope, languageCode)
+	})
+}
+
+// GetCommands возвращает команды бота для области scope и языка languageCode.
+// Пустой список означает, что для этой пары команды не заданы.
+func GetCommands(ctx context.Context, botUsername string, scope mahalo.BotCommandScope, languageCode string) ([]mahalo.BotCommand, error) {
+	if err := validateScope(scope, languageCode); err != nil {
+		return nil, err
+	}
+
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "get_commands", botUsername)
+	var commands []mahalo.BotCommand
+	err := withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		var err error
+		commands, err = bot.GetMyCommandsInScope(ctx, scope, languageCode)
+		return err
+	})
+	return commands, err
+}
+
+// DeleteCommands удаляет команды бота для области scope и языка languageCode —
+// пользователи снова увидят команды более широкой области
+func DeleteCommands(ctx context.Context, botUsername string, scope mahalo.BotCommandScope, languageCode string) error {
+	if err := validateScope(scope, languageCode); err != nil {
+		return err
+	}
+
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "delete_commands", botUsername)
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		return bot.DeleteMyCommands(ctx, scope, languageCode)
+	})
+}
+
+func validateScope(scope mahalo.BotCommandScope, languageCode string) error {
+	if err := scope.Validate(); err != nil {
+		return err
+	}
+	if languageCode != "" {
+		return ValidateLanguageCode(languageCode)
+	}
+	return nil
+}
+
+// field возвращает имя поля манифеста: scoped_commands.<область>[.<язык>]
+func (sc ScopedCommands) field() string {
+	name := "scoped_commands." + sc.Scope.String()
+	if sc.Language != "" {
+		name += "." + sc.Language
+	}
+	return name
+}
+
+// setting возвращает настройку, которая применяется только через Bot API
+func (sc ScopedCommands) setting() setting {
+	return setting{name: sc.field(), text: mahalo.FormatCommandList(sc.Commands), lang: sc.Language,
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, lang string) error {
+			return bot.SetMyCommandsInScope(ctx, sc.Commands, sc.Scope, lang)
+		}}
+}
+
+// validate проверяет область, язык и сами команды
+func (sc ScopedCommands) validate() []string {
+	var problems []string
+	if err := validateScope(sc.Scope, sc.Language); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", sc.field(), err))
+	}
+	if len(sc.Commands) == 0 {
+		problems = append(problems, fmt.Sprintf("%s: пустой список команд, для удаления используйте DeleteCommands", sc.field()))
+	} else if err := mahalo.ValidateCommandList(sc.Commands); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", sc.field(), err))
+	}
+	return problems
+}
+
+// commandScopes возвращает области и языки команд из манифеста
+func (m *Manifest) commandScopes() []commandScope {
+	scopes := make([]commandScope, len(m.ScopedCommands))
+	for i, sc := range m.ScopedCommands {
+		scopes[i] = commandScope{scope: sc.Scope, lang: sc.Language}
+	}
+	return scopes
+}
+
+// readScopedCommands читает команды для областей scopes; пустые списки не попадают в результат
+func readScopedCommands(ctx context.Context, bot *mahalo.BotAPI, scopes []commandScope) ([]ScopedCommands, error) {
+	var out []ScopedCommands
+	for _, cs := range scopes {
+		commands, err := bot.GetMyCommandsInScope(ctx, cs.scope, cs.lang)
+		if err != nil {
+			return nil, err
+		}
+		if len(commands) > 0 {
+			out = append(out, ScopedCommands{Scope: cs.scope, Language: cs.lang, Commands: commands})
+		}
+	}
+	return out, nil
+}
+
+// findScopedCommands возвращает команды с тем же полем манифеста (областью и языком)
+func findScopedCommands(list []ScopedCommands, field string) []mahalo.BotCommand {
+	for _, sc := range list {
+		if sc.field() == field {
+			return sc.Commands
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package ohana
+
+import (
+	"context"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/tg"
+)
+
+// botFatherDialogue описывает команду BotFather, которая выбирает бота,
+// запрашивает один текст и подтверждает изменение
+type botFatherDialogue struct {
+	command string
+	wait    []string
+	success []string
+}
+
+// run проводит диалог в уже открытом подключении
+func (d botFatherDialogue) run(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername, text string) error {
+	return mahalo.RunBotCommand(ctx, api, botFather, botUsername, d.command,
+		[]mahalo.DialogueStep{{Wait: d.wait, Send: text}}, d.success)
+}
+
+const deleteBotConfirmation = "Yes, I am totally sure."
+
+// Диалоги BotFather для настроек бота
+var (
+	setNameDialogue = botFatherDialogue{
+		command: "/setname",
+		wait:    []string{"send me the new name", "choose a name", "what name"},
+		success: []string{"success", "updated", "done", "name updated"},
+	}
+	setDescriptionDialogue = botFatherDialogue{
+		command: "/setdescription",
+		wait:    []string{"send me the new description", "what description", "description for the bot"},
+		success: []string{"success", "updated", "done", "description updated"},
+	}
+	setAboutDialogue = botFatherDialogue{
+		command: "/setabouttext",
+		wait:    []string{"about", "send me", "new text", "about text"},
+		success: []string{"success", "updated", "done", "about section updated"},
+	}
+	setCommandsDialogue = botFatherDialogue{
+		command: "/setcommands",
+		wait:    []string{"send me a list of commands", "list of commands", "command1 - description"},
+		success: []string{"success", "updated", "done", "command list updated"},
+	}
+	deleteBotDialogue = botFatherDialogue{
+		command: "/deletebot",
+		wait:    []string{"are you sure", "confirm", "delete this bot", "yes, i am totally sure"},
+		success: []string{"deleted", "successfully deleted", "bot has been deleted", "done", "bot is gone"},
+	}
+	setPrivacyDialogue = botFatherDialogue{
+		command: "/setprivacy",
+		wait:    []string{"'enable'", "current status", "privacy mode"},
+		success: []string{"success", "new status"},
+	}
+	setJoinGroupsDialogue = botFatherDialogue{
+		command: "/setjoingroups",
+		wait:    []string{"'enable'", "current status", "added to groups"},
+		success: []string{"success", "new status"},
+	}
+	setInlineDialogue = botFatherDialogue{
+		command: "/setinline",
+		wait:    []string{"placeholder", "inline mode"},
+		success: []string{"success", "inline settings updated", "updated"},
+	}
+	setDomainDialogue = botFatherDialogue{
+		command: "/setdomain",
+		wait:    []string{"send me the domain", "domain", "website"},
+		success: []string{"success", "domain updated", "linked"},
+	}
+)
+
+// SetBotPrivacy включает или выключает режим приватности: в режиме приватности
+// бот в группах видит только команды и обращения к себе
+func SetBotPrivacy(botUsername string, enabled bool) error {
+	return SetBotPrivacyContext(context.Background(), botUsername, enabled)
+}
+
+// SetBotPrivacyContext работает как SetBotPrivacy с контекстом ctx
+func SetBotPrivacyContext(ctx context.Context, botUsername string, enabled bool) error {
+	return execBotFatherCommand(ctx, botUsername, setPrivacyDialogue, enableDisable(enabled))
+}
+
+// SetBotJoinGroups разрешает или запрещает добавлять бота в группы
+func SetBotJoinGroups(botUsername string, allowed bool) error {
+	return SetBotJoinGroupsContext(context.Background(), botUsername, allowed)
+}
+
+// SetBotJoinGroupsContext работает как SetBotJoinGroups с контекстом ctx
+func SetBotJoinGroupsContext(ctx context.Context, botUsername string, allowed bool) error {
+	return execBotFatherCommand(ctx, botUsername, setJoinGroupsDialogue, enableDisable(allowed))
+}
+
+// SetBotInline включает inline-режим с подсказкой placeholder в поле ввода.
+// Выключить inline-режим BotFather позволяет только через кнопки /mybots.
+func SetBotInline(botUsername, placeholder string) error {
+	return SetBotInlineContext(context.Background(), botUsername, placeholder)
+}
+
+// SetBotInlineContext работает как SetBotInline с контекстом ctx
+func SetBotInlineContext(ctx context.Context, botUsername, placeholder string) error {
+	return execBotFatherCommand(ctx, botUsername, setInlineDialogue, placeholder)
+}
+
+// SetBotDomain привязывает домен сайта для Telegram Login Widget
+func SetBotDomain(botUsername, domain string) error {
+	return SetBotDomainContext(context.Background(), botUsername, domain)
+}
+
+// SetBotDomainContext работает как SetBotDomain с контекстом ctx
+func SetBotDomainContext(ctx context.Context, botUsername, domain string) error {
+	return execBotFatherCommand(ctx, botUsername, setDomainDialogue, domain)
+}
+
+// SetBotMenuButton настраивает кнопку меню, открывающую веб-приложение по url
+func SetBotMenuButton(botUsername, text, url string) error {
+	return SetBotMenuButtonContext(context.Background(), botUsername, text, url)
+}
+
+// SetBotMenuButtonContext работает как SetBotMenuButton с контекстом ctx
+func SetBotMenuButtonContext(ctx context.Context, botUsername, text, url string) error {
+	return withBotFather(startOperation(ctx, "setmenubutton", botUsername), func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		return setBotMenuButton(ctx, api, botFather, botUsername, text, url)
+	})
+}
+
+// setBotMenuButton проводит двухшаговый диалог /setmenubutton: сначала url, затем текст кнопки
+func setBotMenuButton(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername, text, url string) error {
+	return mahalo.RunBotCommand(ctx, api, botFather, botUsername, "/setmenubutton",
+		[]mahalo.DialogueStep{
+			{Wait: []string{"send me the url", "url", "web app"}, Send: url},
+			{Wait: []string{"title", "button text", "name for the button"}, Send: text},
+		},
+		[]string{"success", "updated", "menu button"})
+}
+
+func enableDisable(enabled bool) string {
+	if enabled {
+		return "Enable"
+	}
+	return "Disable"
+}
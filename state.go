@@ -0,0 +1,286 @@
+package ohana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boriuscastus/ohana/mahalo"
+
+	"github.com/gotd/td/tg"
+)
+
+// BotState — текущее состояние бота, прочитанное через Bot API
+type BotState struct {
+	Username    string
+	Name        string
+	Description string
+	About       string
+	Commands    []mahalo.BotCommand
+	Privacy     bool // режим приватности включен
+	JoinGroups  bool
+	Inline      bool // inline-режим включен (текст подсказки Bot API не отдает)
+	HasUserpic  bool
+	MenuButton  *MenuButton // nil, если кнопка меню не ведет в веб-приложение
+	Webhook     *Webhook    // nil, если вебхук не задан; секрет Bot API не отдает
+	// GroupAdminRights и ChannelAdminRights — права, предлагаемые при добавлении бота администратором
+	GroupAdminRights   mahalo.ChatAdministratorRights
+	ChannelAdminRights mahalo.ChatAdministratorRights
+	// Localized — тексты для запрошенных языков; языки без своих текстов не попадают
+	Localized map[string]LocalizedProfile
+	// ScopedCommands — команды для запрошенных областей; области без команд не попадают
+	ScopedCommands []ScopedCommands
+}
+
+// Токены, полученные в этом процессе, чтобы не спрашивать BotFather повторно
+var tokenCache = struct {
+	sync.Mutex
+	tokens map[string]string
+}{tokens: map[string]string{}}
+
+func rememberToken(botUsername, token string) {
+	tokenCache.Lock()
+	defer tokenCache.Unlock()
+	tokenCache.tokens[strings.ToLower(botUsername)] = token
+}
+
+func cachedToken(botUsername string) (string, bool) {
+	tokenCache.Lock()
+	defer tokenCache.Unlock()
+	token, ok := tokenCache.tokens[strings.ToLower(botUsername)]
+	return token, ok
+}
+
+func forgetToken(botUsername string) {
+	tokenCache.Lock()
+	defer tokenCache.Unlock()
+	delete(tokenCache.tokens, strings.ToLower(botUsername))
+}
+
+// UseBotToken сообщает библиотеке уже известный токен бота: имя, описание, about
+// и команды тогда меняются через Bot API, без диалога с BotFather.
+// Проверяется только формат "<id бота>:<секрет>"; недействующий токен будет забыт
+// при первом ответе 401 от Bot API.
+func UseBotToken(botUsername, token string) error {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	if err := checkTokenFormat(botUsername, token); err != nil {
+		return err
+	}
+	rememberToken(botUsername, token)
+	return nil
+}
+
+// checkTokenFormat проверяет, что токен имеет вид "<числовой id бота>:<секрет>"
+func checkTokenFormat(botUsername, token string) error {
+	id, secret, ok := strings.Cut(token, ":")
+	if !ok || secret == "" || strings.ContainsAny(secret, " \t\n") {
+		return &InvalidTokenError{Username: botUsername, Reason: "ожидается формат <id бота>:<секрет>"}
+	}
+	if n, err := strconv.ParseInt(id, 10, 64); err != nil || n <= 0 {
+		return &InvalidTokenError{Username: botUsername, Reason: "префикс токена не является id бота"}
+	}
+	return nil
+}
+
+// GetBotToken получает текущий токен бота через /token
+func GetBotToken(botUsername string) (token string, err error) {
+	return GetBotTokenContext(context.Background(), botUsername)
+}
+
+// GetBotTokenContext работает как GetBotToken с контекстом ctx
+func GetBotTokenContext(ctx context.Context, botUsername string) (token string, err error) {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	err = withBotFather(startOperation(ctx, "token", botUsername), func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		token, err = getBotToken(ctx, api, botFather, botUsername)
+		return err
+	})
+	return token, err
+}
+
+// getBotToken возвращает токен из кэша или спрашивает его у BotFather
+func getBotToken(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string) (string, error) {
+	if token, ok := cachedToken(botUsername); ok {
+		return token, nil
+	}
+
+	if err := mahalo.SelectBot(ctx, api, botFather, "/token", botUsername); err != nil {
+		return "", err
+	}
+
+	resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+		[]string{"use this token", "http api", "token"},
+		30*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("ожидание токена: %w", err)
+	}
+
+	token := mahalo.ParseToken(resp)
+	if token == "" {
+		return "", fmt.Errorf("не удалось извлечь токен бота @%s из ответа BotFather", botUsername)
+	}
+
+	// Токен действует, даже если его не удалось сохранить, — возвращаем его вместе с ошибкой
+	return token, acceptToken(ctx, botUsername, token)
+}
+
+// RevokeBotToken отзывает текущий токен бота через /revoke и возвращает новый
+func RevokeBotToken(ctx context.Context, botUsername string) (token string, err error) {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "revoke", botUsername)
+	err = withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		if err := mahalo.SelectBot(ctx, api, botFather, "/revoke", botUsername); err != nil {
+			return err
+		}
+
+		resp, err := mahalo.WaitForResponseWithChecks(ctx, api, botFather,
+			[]string{"token was replaced", "new token", "use this token"},
+			30*time.Second)
+		if err != nil {
+			return fmt.Errorf("ожидание нового токена: %w", err)
+		}
+
+		token = mahalo.ParseToken(resp)
+		if token == "" {
+			return fmt.Errorf("не удалось извлечь новый токен бота @%s из ответа BotFather", botUsername)
+		}
+		return acceptToken(ctx, botUsername, token)
+	})
+	return token, err
+}
+
+// newBotAPI создает клиент Bot API для токена с адресом и HTTP-клиентом из конфига
+func newBotAPI(token string) *mahalo.BotAPI {
+	bot := mahalo.NewBotAPI(token)
+	if config != nil && config.BotAPIURL != "" {
+		bot.BaseURL = config.BotAPIURL
+	}
+	if config != nil && config.HTTPClient != nil {
+		bot.HTTPClient = config.HTTPClient
+	}
+	return bot
+}
+
+// verifyToken проверяет через getMe, что токен рабочий и принадлежит @botUsername.
+// Явное несовпадение — *InvalidTokenError. Если getMe недоступен (сеть, 5xx, 429),
+// возвращается *TokenUnverifiedError: токен, скорее всего, рабочий, но не проверен.
+func verifyToken(ctx context.Context, botUsername, token string) error {
+	if err := checkTokenFormat(botUsername, token); err != nil {
+		return err
+	}
+
+	me, err := newBotAPI(token).GetMe(ctx)
+	var apiErr *mahalo.BotAPIError
+	switch {
+	case errors.As(err, &apiErr) && (apiErr.Code == 401 || apiErr.Code == 404):
+		return &InvalidTokenError{Username: botUsername, Reason: apiErr.Description}
+	case err != nil:
+		return &TokenUnverifiedError{Username: botUsername, Token: token, Err: err}
+	case !me.IsBot:
+		return &InvalidTokenError{Username: botUsername, Reason: "getMe вернул не бота"}
+	case !strings.EqualFold(me.Username, botUsername):
+		return &InvalidTokenError{Username: botUsername, Reason: fmt.Sprintf("токен принадлежит @%s", me.Username)}
+	case !strings.HasPrefix(token, strconv.FormatInt(me.ID, 10)+":"):
+		return &InvalidTokenError{Username: botUsername, Reason: "id бота не совпадает с префиксом токена"}
+	}
+
+	mahalo.Logger(ctx).Debug("токен проверен через getMe", "user_id", me.ID)
+	return nil
+}
+
+// ReadBotState читает текущее состояние бота: токен берется у BotFather,
+// остальное — через Bot API. Для языков languages дополнительно читаются их тексты.
+func ReadBotState(ctx context.Context, botUsername string, languages ...string) (state *BotState, err error) {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "read_state", botUsername)
+	err = withBotFather(ctx, func(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass) error {
+		state, err = readBotState(ctx, api, botFather, botUsername, languages, nil)
+		return err
+	})
+	return state, err
+}
+
+// readBotState читает состояние бота в уже открытом подключении.
+// Команды для отдельных областей читаются только для scopes.
+func readBotState(ctx context.Context, api *tg.Client, botFather tg.InputPeerClass, botUsername string, languages []string, scopes []commandScope) (*BotState, error) {
+	token, err := getBotToken(ctx, api, botFather, botUsername)
+	if err != nil {
+		return nil, err
+	}
+	bot := newBotAPI(token)
+
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &BotState{
+		Username:   me.Username,
+		Privacy:    !me.CanReadAllGroupMessages,
+		JoinGroups: me.CanJoinGroups,
+		Inline:     me.SupportsInlineQueries,
+	}
+
+	if state.Name, err = bot.GetMyName(ctx, ""); err != nil {
+		return nil, err
+	}
+	if state.Description, err = bot.GetMyDescription(ctx, ""); err != nil {
+		return nil, err
+	}
+	if state.About, err = bot.GetMyShortDescription(ctx, ""); err != nil {
+		return nil, err
+	}
+	if state.Commands, err = bot.GetMyCommands(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	photos, err := bot.CountProfilePhotos(ctx, me.ID)
+	if err != nil {
+		return nil, err
+	}
+	state.HasUserpic = photos > 0
+
+	button, err := bot.GetChatMenuButton(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if button.Type == "web_app" && button.WebApp != nil {
+		state.MenuButton = &MenuButton{Text: button.Text, URL: button.WebApp.URL}
+	}
+
+	webhook, err := bot.GetWebhookInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state.Webhook = webhookState(webhook)
+
+	if state.GroupAdminRights, err = bot.GetMyDefaultAdministratorRights(ctx, false); err != nil {
+		return nil, err
+	}
+	if state.ChannelAdminRights, err = bot.GetMyDefaultAdministratorRights(ctx, true); err != nil {
+		return nil, err
+	}
+
+	for _, lang := range languages {
+		p, err := readLocalized(ctx, bot, lang)
+		if err != nil {
+			return nil, err
+		}
+		if p.IsZero() {
+			continue
+		}
+		if state.Localized == nil {
+			state.Localized = map[string]LocalizedProfile{}
+		}
+		state.Localized[lang] = p
+	}
+
+	if state.ScopedCommands, err = readScopedCommands(ctx, bot, scopes); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
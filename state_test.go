@@ -0,0 +1,103 @@
+package ohana
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setupGetMe направляет Bot API на тестовый сервер, который отвечает на getMe статусом status и телом body
+func setupGetMe(t *testing.T, status int, body string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	prev := config
+	t.Cleanup(func() { config = prev })
+	config = &Config{BotAPIURL: srv.URL, HTTPClient: srv.Client()}
+}
+
+func TestVerifyToken(t *testing.T) {
+	const token = "123456:secret"
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		body   string
+		want   string // "", "invalid" или "unverified"
+	}{
+		{"совпадает", token, 200, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"MyBot"}}`, ""},
+		{"отозван", token, 401, `{"ok":false,"error_code":401,"description":"Unauthorized"}`, "invalid"},
+		{"чужой бот", token, 200, `{"ok":true,"result":{"id":123456,"is_bot":true,"username":"otherbot"}}`, "invalid"},
+		{"не бот", token, 200, `{"ok":true,"result":{"id":123456,"is_bot":false,"username":"mybot"}}`, "invalid"},
+		{"id не совпадает", token, 200, `{"ok":true,"result":{"id":654321,"is_bot":true,"username":"mybot"}}`, "invalid"},
+		{"плохой формат", "garbage", 200, `{"ok":true,"result":{}}`, "invalid"},
+		{"getMe недоступен", token, 502, `{"ok":false,"error_code":502,"description":"Bad Gateway"}`, "unverified"},
+		{"не JSON", token, 503, `<html></html>`, "unverified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupGetMe(t, tt.status, tt.body)
+			err := verifyToken(context.Background(), "mybot", tt.token)
+
+			var (
+				invalid    *InvalidTokenError
+				unverified *TokenUnverifiedError
+			)
+			switch tt.want {
+			case "":
+				if err != nil {
+					t.Errorf("verifyToken: %v", err)
+				}
+			case "invalid":
+				if !errors.As(err, &invalid) {
+					t.Errorf("verifyToken = %v, ожидалась *InvalidTokenError", err)
+				}
+			case "unverified":
+				if !errors.As(err, &unverified) || unverified.Token != tt.token {
+					t.Errorf("verifyToken = %v, ожидалась *TokenUnverifiedError с токеном", err)
+				}
+				if ErrorCode(err) != CodeTokenUnverified {
+					t.Errorf("ErrorCode = %q, ожидался %q", ErrorCode(err), CodeTokenUnverified)
+				}
+			}
+		})
+	}
+}
+
+func TestAcceptTokenUnverified(t *testing.T) {
+	setupGetMe(t, 500, `{"ok":false,"error_code":500,"description":"Internal Server Error"}`)
+	var stored string
+	config.TokenSink = TokenSinkFunc(func(_ context.Context, _, token string) error {
+		stored = token
+		return nil
+	})
+	t.Cleanup(func() { forgetToken("mybot") })
+
+	// Непроверенный токен не теряется, но и успехом не считается
+	err := acceptToken(context.Background(), "mybot", "123456:secret")
+	var unverified *TokenUnverifiedError
+	if !errors.As(err, &unverified) {
+		t.Fatalf("acceptToken = %v, ожидалась *TokenUnverifiedError", err)
+	}
+	if stored != "123456:secret" {
+		t.Errorf("в TokenSink передано %q", stored)
+	}
+	if token, ok := cachedToken("mybot"); !ok || token != "123456:secret" {
+		t.Errorf("токен не запомнен: %q, %v", token, ok)
+	}
+
+	// Ошибка хранилища важнее: без нее токен может быть потерян
+	config.TokenSink = TokenSinkFunc(func(context.Context, string, string) error { return errors.New("диск заполнен") })
+	var storeErr *TokenStoreError
+	if err := acceptToken(context.Background(), "mybot", "123456:secret"); !errors.As(err, &storeErr) {
+		t.Errorf("acceptToken = %v, ожидалась *TokenStoreError", err)
+	}
+}
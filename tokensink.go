@@ -0,0 +1,278 @@
+package ohana
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// TokenSink сохраняет токен бота сразу после того, как его выдал BotFather:
+// при создании бота, /revoke и получении токена через /token
+type TokenSink interface {
+	StoreToken(ctx context.Context, botUsername, token string) error
+}
+
+// TokenSinkFunc позволяет использовать функцию как TokenSink
+type TokenSinkFunc func(ctx context.Context, botUsername, token string) error
+
+func (f TokenSinkFunc) StoreToken(ctx context.Context, botUsername, token string) error {
+	return f(ctx, botUsername, token)
+}
+
+// WithTokenSink задает, куда сохранять выданные токены
+func WithTokenSink(sink TokenSink) Option {
+	return func(c *Config) { c.TokenSink = sink }
+}
+
+// TokenStoreError — BotFather выдал токен, но TokenSink не смог его сохранить.
+// Бот при этом уже существует (или старый токен уже отозван), поэтому токен
+// возвращается вместе с ошибкой — его нужно сохранить самостоятельно.
+type TokenStoreError struct {
+	Username string
+	Token    string
+	Err      error
+}
+
+func (e *TokenStoreError) Error() string {
+	return fmt.Sprintf("не удалось сохранить токен @%s: %v", e.Username, e.Err)
+}
+
+func (e *TokenStoreError) Unwrap() error { return e.Err }
+
+// acceptToken проверяет токен, выданный BotFather, через getMe, запоминает его в процессе
+// и передает в TokenSink из конфига. Ошибка не означает, что бота нет: вызывающий код
+// должен вернуть username и токен вместе с ней. Непроверенный токен (*TokenUnverifiedError)
+// тоже запоминается и сохраняется — иначе он будет потерян.
+func acceptToken(ctx context.Context, botUsername, token string) error {
+	ctx = withOperationBot(ctx, botUsername)
+	verifyErr := verifyToken(ctx, botUsername, token)
+	var unverified *TokenUnverifiedError
+	if verifyErr != nil && !errors.As(verifyErr, &unverified) {
+		return verifyErr
+	}
+
+	rememberToken(botUsername, token)
+	if config != nil && config.TokenSink != nil {
+		if err := config.TokenSink.StoreToken(ctx, botUsername, token); err != nil {
+			return &TokenStoreError{Username: botUsername, Token: token, Err: err}
+		}
+		mahalo.Logger(ctx).Debug("токен сохранен")
+	}
+	return verifyErr
+}
+
+// ========== ФАЙЛ ==========
+
+// FileTokenSink пишет токен каждого бота в отдельный файл <Dir>/<username>.token
+// с правами 0600 через атомарную замену
+type FileTokenSink struct {
+	Dir string
+}
+
+func (s *FileTokenSink) StoreToken(ctx context.Context, botUsername, token string) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("не удалось создать каталог %s: %w", s.Dir, err)
+	}
+	path := filepath.Join(s.Dir, strings.ToLower(botUsername)+".token")
+	return mahalo.WriteFileAtomic(path, []byte(token+"\n"), 0o600)
+}
+
+// ========== .ENV ==========
+
+// EnvFileTokenSink добавляет или обновляет строку KEY=token в .env-файле,
+// не трогая остальные строки. Файл пишется атомарно с правами 0600.
+type EnvFileTokenSink struct {
+	Path string
+	// Key возвращает имя переменной для бота; по умолчанию MY_BOT_TOKEN для @my_bot
+	Key func(botUsername string) string
+
+	mu sync.Mutex
+}
+
+func (s *EnvFileTokenSink) StoreToken(ctx context.Context, botUsername, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := envTokenKey(botUsername)
+	if s.Key != nil {
+		key = s.Key(botUsername)
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("не удалось прочитать %s: %w", s.Path, err)
+	}
+
+	var out bytes.Buffer
+	replaced := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		name, _, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		if ok && strings.TrimSpace(name) == key {
+			if replaced {
+				continue // дубликаты ключа убираем
+			}
+			line = key + "=" + token
+			replaced = true
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("не удалось прочитать %s: %w", s.Path, err)
+	}
+	if !replaced {
+		out.WriteString(key + "=" + token + "\n")
+	}
+
+	return mahalo.WriteFileAtomic(s.Path, out.Bytes(), 0o600)
+}
+
+// envTokenKey превращает username в имя переменной: my_bot → MY_BOT_TOKEN
+func envTokenKey(botUsername string) string {
+	return strings.ToUpper(botUsername) + "_TOKEN"
+}
+
+// ========== ЗАШИФРОВАННОЕ ХРАНИЛИЩЕ ==========
+
+// Параметры шифрования хранилища
+const (
+	keystoreVersion    = 1
+	keystoreIterations = 600_000
+	keystoreSaltSize   = 16
+)
+
+// KeystoreTokenSink хранит токены всех ботов в одном файле, зашифрованном AES-256-GCM.
+// Ключ выводится из Passphrase через PBKDF2-SHA256; при каждой записи соль и nonce новые.
+type KeystoreTokenSink struct {
+	Path       string
+	Passphrase string
+
+	mu sync.Mutex
+}
+
+// keystoreFile — формат файла хранилища
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s *KeystoreTokenSink) StoreToken(ctx context.Context, botUsername, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[strings.ToLower(botUsername)] = token
+	return s.save(tokens)
+}
+
+// Token возвращает сохраненный токен бота
+func (s *KeystoreTokenSink) Token(botUsername string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[strings.ToLower(mahalo.NormalizeBotUsername(botUsername))]
+	if !ok {
+		return "", fmt.Errorf("в хранилище нет токена @%s", botUsername)
+	}
+	return token, nil
+}
+
+// load расшифровывает хранилище; отсутствующий файл — пустое хранилище
+func (s *KeystoreTokenSink) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать хранилище: %w", err)
+	}
+
+	var f keystoreFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("хранилище %s повреждено: %w", s.Path, err)
+	}
+	if f.Version != keystoreVersion {
+		return nil, fmt.Errorf("неизвестная версия хранилища: %d", f.Version)
+	}
+
+	aead, err := s.cipher(f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать хранилище: неверная парольная фраза или файл поврежден")
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("хранилище %s повреждено: %w", s.Path, err)
+	}
+	return tokens, nil
+}
+
+func (s *KeystoreTokenSink) save(tokens map[string]string) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	f := keystoreFile{Version: keystoreVersion, Salt: make([]byte, keystoreSaltSize)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	aead, err := s.cipher(f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return mahalo.WriteFileAtomic(s.Path, data, 0o600)
+}
+
+func (s *KeystoreTokenSink) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.Passphrase == "" {
+		return nil, fmt.Errorf("не задана парольная фраза хранилища")
+	}
+	key, err := pbkdf2.Key(sha256.New, s.Passphrase, salt, keystoreIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,129 @@
+package ohana
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkPerm проверяет, что файл с токенами закрыт от других пользователей
+func checkPerm(t *testing.T, path string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("права %s: %o, ожидались 0600", path, perm)
+	}
+}
+
+func TestFileTokenSink(t *testing.T) {
+	sink := &FileTokenSink{Dir: filepath.Join(t.TempDir(), "tokens")}
+	ctx := context.Background()
+	for _, token := range []string{"1:old", "1:new"} {
+		if err := sink.StoreToken(ctx, "My_Bot", token); err != nil {
+			t.Fatalf("StoreToken: %v", err)
+		}
+	}
+
+	path := filepath.Join(sink.Dir, "my_bot.token")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "1:new" {
+		t.Errorf("в файле %q, ожидалось %q", got, "1:new")
+	}
+	checkPerm(t, path)
+}
+
+func TestEnvFileTokenSink(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // "" — файла нет
+		key      func(string) string
+		want     string
+	}{
+		{"новый файл", "", nil, "MY_BOT_TOKEN=1:new\n"},
+		{"остальные строки не трогаются", "# боты\nOTHER=1\n\nexport MY_BOT_TOKEN=1:old\nLAST=2\n", nil,
+			"# боты\nOTHER=1\n\nMY_BOT_TOKEN=1:new\nLAST=2\n"},
+		{"дубликаты ключа убираются", "MY_BOT_TOKEN=1:a\nMY_BOT_TOKEN=1:b\n", nil, "MY_BOT_TOKEN=1:new\n"},
+		{"ключ дописывается в конец", "OTHER=1\n", nil, "OTHER=1\nMY_BOT_TOKEN=1:new\n"},
+		{"свое имя переменной", "", func(string) string { return "BOT_TOKEN" }, "BOT_TOKEN=1:new\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sink := &EnvFileTokenSink{Path: path, Key: tt.key}
+			if err := sink.StoreToken(context.Background(), "my_bot", "1:new"); err != nil {
+				t.Fatalf("StoreToken: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("файл:\n%s\nожидалось:\n%s", data, tt.want)
+			}
+			checkPerm(t, path)
+		})
+	}
+}
+
+func TestKeystoreTokenSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.keystore")
+	sink := &KeystoreTokenSink{Path: path, Passphrase: "correct horse"}
+	ctx := context.Background()
+	if err := sink.StoreToken(ctx, "first_bot", "1:first"); err != nil {
+		t.Fatalf("StoreToken: %v", err)
+	}
+	if err := sink.StoreToken(ctx, "Second_Bot", "2:second"); err != nil {
+		t.Fatalf("StoreToken: %v", err)
+	}
+	checkPerm(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "1:first") || strings.Contains(string(data), "2:second") {
+		t.Error("токены лежат в хранилище открытым текстом")
+	}
+
+	// Новый экземпляр с той же фразой читает оба токена, username нормализуется
+	reopened := &KeystoreTokenSink{Path: path, Passphrase: "correct horse"}
+	for username, want := range map[string]string{"first_bot": "1:first", "@second_bot": "2:second"} {
+		if got, err := reopened.Token(username); err != nil || got != want {
+			t.Errorf("Token(%q) = %q, %v; ожидалось %q", username, got, err, want)
+		}
+	}
+	if _, err := reopened.Token("missing_bot"); err == nil {
+		t.Error("ожидалась ошибка для бота без токена")
+	}
+
+	wrong := &KeystoreTokenSink{Path: path, Passphrase: "wrong"}
+	if _, err := wrong.Token("first_bot"); err == nil || !strings.Contains(err.Error(), "неверная парольная фраза") {
+		t.Errorf("с неверной фразой: %v", err)
+	}
+	// Запись с неверной фразой не должна затереть хранилище
+	if err := wrong.StoreToken(ctx, "third_bot", "3:third"); err == nil {
+		t.Error("ожидалась ошибка записи с неверной фразой")
+	}
+	if got, err := reopened.Token("first_bot"); err != nil || got != "1:first" {
+		t.Errorf("после записи с неверной фразой: %q, %v", got, err)
+	}
+
+	if err := (&KeystoreTokenSink{Path: path}).StoreToken(ctx, "first_bot", "1:x"); err == nil {
+		t.Error("ожидалась ошибка без парольной фразы")
+	}
+}
@@ -0,0 +1,134 @@
+package ohana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/boriuscastus/ohana/mahalo"
+)
+
+// Webhook — вебхук бота в манифесте. Пустые MaxConnections и AllowedUpdates означают «не трогать».
+type Webhook struct {
+	URL string `json:"url"`
+	// SecretToken приходит в заголовке X-Telegram-Bot-Api-Secret-Token.
+	// Bot API его не возвращает, поэтому план и проверка расхождений его не сравнивают.
+	SecretToken    string   `json:"secret_token,omitempty"`
+	MaxConnections int      `json:"max_connections,omitempty"`
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
+
+// MaxWebhookConnections — предел max_connections в Bot API
+const MaxWebhookConnections = 100
+
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// SetWebhook задает вебхук бота через Bot API.
+// Если токен бота неизвестен, он запрашивается у BotFather через /token.
+func SetWebhook(ctx context.Context, botUsername string, w Webhook) error {
+	if problems := w.validate(); len(problems) > 0 {
+		return &ManifestError{Problems: problems}
+	}
+
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "set_webhook", botUsername)
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		return w.setting().botAPI(ctx, bot, "")
+	})
+}
+
+// GetWebhookInfo возвращает текущие настройки вебхука бота
+func GetWebhookInfo(ctx context.Context, botUsername string) (*mahalo.WebhookInfo, error) {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "get_webhook", botUsername)
+	var info *mahalo.WebhookInfo
+	err := withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		var err error
+		info, err = bot.GetWebhookInfo(ctx)
+		return err
+	})
+	return info, err
+}
+
+// DeleteWebhook удаляет вебхук бота; dropPendingUpdates — сбросить накопившиеся обновления
+func DeleteWebhook(ctx context.Context, botUsername string, dropPendingUpdates bool) error {
+	botUsername = mahalo.NormalizeBotUsername(botUsername)
+	ctx = startOperation(ctx, "delete_webhook", botUsername)
+	return withBotAPI(ctx, botUsername, func(ctx context.Context, bot *mahalo.BotAPI) error {
+		return bot.DeleteWebhook(ctx, dropPendingUpdates)
+	})
+}
+
+// setting возвращает настройку, которая применяется только через Bot API
+func (w Webhook) setting() setting {
+	return setting{name: "webhook", text: w.URL,
+		botAPI: func(ctx context.Context, bot *mahalo.BotAPI, _ string) error {
+			if w.SecretToken != "" {
+				mahalo.RegisterSecret(w.SecretToken)
+			}
+			return bot.SetWebhook(ctx, mahalo.WebhookParams{
+				URL:            w.URL,
+				SecretToken:    w.SecretToken,
+				MaxConnections: w.MaxConnections,
+				AllowedUpdates: w.AllowedUpdates,
+			})
+		}}
+}
+
+// validate проверяет вебхук по правилам Bot API
+func (w Webhook) validate() []string {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, "webhook."+fmt.Sprintf(format, args...))
+	}
+
+	if u, err := url.Parse(w.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		addf("url: %q — ожидается https-ссылка", w.URL)
+	}
+	if w.SecretToken != "" && !secretTokenPattern.MatchString(w.SecretToken) {
+		addf("secret_token: допустимы 1–256 символов A-Z, a-z, 0-9, _ и -")
+	}
+	if w.MaxConnections < 0 || w.MaxConnections > MaxWebhookConnections {
+		addf("max_connections: %d, допустимо от 1 до %d", w.MaxConnections, MaxWebhookConnections)
+	}
+	seen := map[string]bool{}
+	for _, u := range w.AllowedUpdates {
+		switch {
+		case strings.TrimSpace(u) == "":
+			addf("allowed_updates: пустой тип обновления")
+		case seen[u]:
+			addf("allowed_updates: %q повторяется", u)
+		}
+		seen[u] = true
+	}
+	return problems
+}
+
+// webhookState переводит ответ getWebhookInfo в вид манифеста; nil — вебхук не задан
+func webhookState(info *mahalo.WebhookInfo) *Webhook {
+	if info.URL == "" {
+		return nil
+	}
+	return &Webhook{URL: info.URL, MaxConnections: info.MaxConnections, AllowedUpdates: info.AllowedUpdates}
+}
+
+// formatWebhook приводит вебхук к сравнимой строке с полями, заданными в want
+func formatWebhook(w *Webhook, want *Webhook) string {
+	if w == nil {
+		return "нет"
+	}
+	out := w.URL
+	if want.MaxConnections > 0 {
+		out += " max_connections=" + strconv.Itoa(w.MaxConnections)
+	}
+	if len(want.AllowedUpdates) > 0 {
+		// Порядок типов не важен
+		updates := slices.Sorted(slices.Values(w.AllowedUpdates))
+		out += " allowed_updates=[" + strings.Join(updates, ",") + "]"
+	}
+	return out
+}